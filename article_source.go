@@ -0,0 +1,84 @@
+package news_api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// ArticleSource is the source an article was published by. NewsAPI sends a
+// null id for sources it does not index, which is kept as a nil ID.
+type ArticleSource struct {
+	ID   *string `json:"id"`
+	Name string  `json:"name"`
+}
+
+// NewArticleSource returns an ArticleSource with the given id and name. An
+// empty id gives a nil ID.
+func NewArticleSource(id, name string) ArticleSource {
+	source := ArticleSource{Name: name}
+	if id != "" {
+		source.ID = &id
+	}
+	return source
+}
+
+// IDOrEmpty returns the source id, or "" when it is null.
+func (s ArticleSource) IDOrEmpty() string {
+	if s.ID == nil {
+		return ""
+	}
+	return *s.ID
+}
+
+// Map returns the source in the map[string]interface{} form Articles.Source
+// was decoded into before it was typed, with a nil "id" for null ids.
+//
+// Deprecated: read ID and Name instead. Map is kept so that code written as
+// article.Source.(map[string]interface{}) can move to article.Source.Map().
+func (s ArticleSource) Map() map[string]interface{} {
+	var id interface{}
+	if s.ID != nil {
+		id = *s.ID
+	}
+	return map[string]interface{}{"id": id, "name": s.Name}
+}
+
+// UnmarshalJSON accepts the {"id": ..., "name": ...} object NewsAPI sends,
+// with a string, number or null id, as well as a null or bare string source.
+func (s *ArticleSource) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = ArticleSource{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		*s = ArticleSource{Name: name}
+		return nil
+	}
+	var raw struct {
+		ID   interface{} `json:"id"`
+		Name string      `json:"name"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+	*s = ArticleSource{Name: raw.Name}
+	switch id := raw.ID.(type) {
+	case string:
+		s.ID = &id
+	case json.Number:
+		value := id.String()
+		s.ID = &value
+	case bool:
+		value := strconv.FormatBool(id)
+		s.ID = &value
+	}
+	return nil
+}
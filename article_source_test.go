@@ -0,0 +1,60 @@
+package news_api_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticleSource(t *testing.T) {
+	decode := func(t *testing.T, source string) news_api.ArticleSource {
+		var article news_api.Articles
+		assert.Nil(t, json.Unmarshal([]byte(`{"source":`+source+`,"title":"Apple"}`), &article))
+		assert.Equal(t, "Apple", article.Title)
+		return article.Source
+	}
+
+	t.Run("Source with id", func(t *testing.T) {
+		source := decode(t, `{"id":"bbc-news","name":"BBC News"}`)
+		assert.Equal(t, "bbc-news", *source.ID)
+		assert.Equal(t, "BBC News", source.Name)
+		assert.Equal(t, "bbc-news", source.IDOrEmpty())
+	})
+
+	t.Run("Null id", func(t *testing.T) {
+		source := decode(t, `{"id":null,"name":"Example Blog"}`)
+		assert.Nil(t, source.ID)
+		assert.Equal(t, "", source.IDOrEmpty())
+		assert.Equal(t, "Example Blog", source.Name)
+		assert.Nil(t, decode(t, `{"name":"Example Blog"}`).ID)
+	})
+
+	t.Run("Loose shapes", func(t *testing.T) {
+		assert.Equal(t, news_api.ArticleSource{}, decode(t, `null`))
+		assert.Equal(t, news_api.ArticleSource{Name: "Reuters"}, decode(t, `"Reuters"`))
+		assert.Equal(t, "42", decode(t, `{"id":42,"name":"Numbered"}`).IDOrEmpty())
+		var article news_api.Articles
+		assert.NotNil(t, json.Unmarshal([]byte(`{"source":[1]}`), &article))
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		for _, source := range []news_api.ArticleSource{news_api.NewArticleSource("bbc-news", "BBC News"), news_api.NewArticleSource("", "Example Blog")} {
+			encoded, err := json.Marshal(news_api.Articles{Source: source})
+			assert.Nil(t, err)
+			var article news_api.Articles
+			assert.Nil(t, json.Unmarshal(encoded, &article))
+			assert.Equal(t, source, article.Source)
+		}
+		encoded, err := json.Marshal(news_api.NewArticleSource("", "Example Blog"))
+		assert.Nil(t, err)
+		assert.Equal(t, `{"id":null,"name":"Example Blog"}`, string(encoded))
+	})
+
+	t.Run("Map keeps the old shape", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{"id": "bbc-news", "name": "BBC News"}, news_api.NewArticleSource("bbc-news", "BBC News").Map())
+		assert.Equal(t, map[string]interface{}{"id": nil, "name": "Example Blog"}, news_api.NewArticleSource("", "Example Blog").Map())
+	})
+}
@@ -0,0 +1,312 @@
+package news_api
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedDAO wraps a NewsAPIDAO and keeps successful responses in memory.
+// Entries are keyed on the host, the apiKey parameter and the canonical form
+// of the request URL (see Canonicalize), so the order of parameters and of
+// comma separated values does not matter. Errors are never
+// cached. A CachedDAO is safe for concurrent use.
+type CachedDAO struct {
+	dao        NewsAPIDAO
+	ttl        map[string]time.Duration
+	maxEntries int
+	now        func() time.Time
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key      string
+	endpoint string
+	expires  time.Time
+	news     *NewsResp
+	sources  *SourcesResp
+}
+
+// CacheOption configures a CachedDAO.
+type CacheOption func(*CachedDAO) error
+
+const defaultCacheEntries = 1000
+
+var defaultCacheTTL = map[string]time.Duration{
+	EndpointEverything:   5 * time.Minute,
+	EndpointTopHeadlines: 5 * time.Minute,
+	EndpointSources:      24 * time.Hour,
+}
+
+// WithCacheTTL sets how long responses from endpoint stay cached. The
+// defaults are 5 minutes for everything and top-headlines and 24 hours for
+// sources. A ttl of 0 disables caching for the endpoint.
+func WithCacheTTL(endpoint string, ttl time.Duration) CacheOption {
+	return func(c *CachedDAO) error {
+		if _, ok := defaultCacheTTL[endpoint]; !ok {
+			return errors.New("invalid endpoint")
+		}
+		if ttl < 0 {
+			return errors.New("cache ttl should be greaterthan equalto 0")
+		}
+		c.ttl[endpoint] = ttl
+		return nil
+	}
+}
+
+// WithCacheSize bounds the number of cached responses. The least recently
+// used entry is evicted first. The default is 1000.
+func WithCacheSize(maxEntries int) CacheOption {
+	return func(c *CachedDAO) error {
+		if maxEntries < 1 {
+			return errors.New("cache size should be greaterthan equalto 1")
+		}
+		c.maxEntries = maxEntries
+		return nil
+	}
+}
+
+func NewCachedDAO(dao NewsAPIDAO, opts ...CacheOption) (*CachedDAO, error) {
+	if dao == nil {
+		return nil, errors.New("dao is nil")
+	}
+	c := &CachedDAO{
+		dao:        dao,
+		ttl:        map[string]time.Duration{},
+		maxEntries: defaultCacheEntries,
+		now:        time.Now,
+		logger:     discardLogger,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+	}
+	for endpoint, ttl := range defaultCacheTTL {
+		c.ttl[endpoint] = ttl
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *CachedDAO) GetNews(apiURL string) (NewsResp, error) {
+	return c.GetNewsContext(context.Background(), apiURL)
+}
+
+func (c *CachedDAO) GetNewsContext(ctx context.Context, apiURL string) (NewsResp, error) {
+	key, endpoint := cacheKey(apiURL)
+	if entry := c.get(key); entry != nil && entry.news != nil {
+		c.logLookup(ctx, "newsapi cache hit", key, endpoint)
+		return copyNewsResp(*entry.news), nil
+	}
+	c.logLookup(ctx, "newsapi cache miss", key, endpoint)
+	resp, err := c.dao.GetNewsContext(ctx, apiURL)
+	if err != nil {
+		return resp, err
+	}
+	stored := copyNewsResp(resp)
+	c.put(&cacheEntry{key: key, endpoint: endpoint, news: &stored})
+	return resp, nil
+}
+
+func (c *CachedDAO) GetSources(apiURL string) (SourcesResp, error) {
+	return c.GetSourcesContext(context.Background(), apiURL)
+}
+
+func (c *CachedDAO) GetSourcesContext(ctx context.Context, apiURL string) (SourcesResp, error) {
+	key, endpoint := cacheKey(apiURL)
+	if entry := c.get(key); entry != nil && entry.sources != nil {
+		c.logLookup(ctx, "newsapi cache hit", key, endpoint)
+		return copySourcesResp(*entry.sources), nil
+	}
+	c.logLookup(ctx, "newsapi cache miss", key, endpoint)
+	resp, err := c.dao.GetSourcesContext(ctx, apiURL)
+	if err != nil {
+		return resp, err
+	}
+	stored := copySourcesResp(resp)
+	c.put(&cacheEntry{key: key, endpoint: endpoint, sources: &stored})
+	return resp, nil
+}
+
+// Invalidate drops the cached response for apiURL, if any.
+func (c *CachedDAO) Invalidate(apiURL string) {
+	key, _ := cacheKey(apiURL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// InvalidateEndpoint drops every cached response of endpoint.
+func (c *CachedDAO) InvalidateEndpoint(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*cacheEntry).endpoint == endpoint {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Purge empties the cache.
+func (c *CachedDAO) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = map[string]*list.Element{}
+}
+
+// Len returns the number of cached responses, including expired ones that
+// have not been evicted yet.
+func (c *CachedDAO) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *CachedDAO) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil
+	}
+	c.lru.MoveToFront(element)
+	return entry
+}
+
+func (c *CachedDAO) put(entry *cacheEntry) {
+	ttl, ok := c.ttl[entry.endpoint]
+	if !ok {
+		ttl = c.ttl[EndpointEverything]
+	}
+	if ttl == 0 {
+		return
+	}
+	entry.expires = c.now().Add(ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		c.remove(element)
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		evicted := c.lru.Back().Value.(*cacheEntry)
+		c.remove(c.lru.Back())
+		c.logLookup(context.Background(), "newsapi cache eviction", evicted.key, evicted.endpoint)
+	}
+}
+
+func (c *CachedDAO) logLookup(ctx context.Context, message, key, endpoint string) {
+	c.logger.LogAttrs(ctx, slog.LevelDebug, message, slog.String("endpoint", endpoint), slog.String("key", RedactURL(key)))
+}
+
+func (c *CachedDAO) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+// listQueryParams hold comma separated values whose order has no meaning.
+var listQueryParams = map[string]bool{"searchIn": true, "sources": true, "domains": true, "excludeDomains": true, "country": true, "category": true, "language": true}
+
+// cacheKey canonicalizes apiURL with Canonicalize when it is a valid query,
+// and otherwise by sorting its parameters and the values of list parameters.
+// The key keeps the scheme and host, relative URLs being on
+// https://newsapi.org, and a SHA-256 of any apiKey parameter, so responses
+// from other servers or for other keys are not shared. It also returns the
+// endpoint the URL points at.
+func cacheKey(apiURL string) (string, string) {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return apiURL, ""
+	}
+	// The endpoint only picks the TTL, so it is read from the path on any
+	// host.
+	base, _ := url.Parse(defaultBaseURL)
+	if parsed.Host != "" {
+		base.Scheme, base.Host = parsed.Scheme, parsed.Host
+	}
+	endpoint := endpointFromURL(parsed, base)
+	values := parsed.Query()
+	apiKey := ""
+	for key := range values {
+		if strings.EqualFold(key, "apiKey") {
+			apiKey = values.Get(key)
+			delete(values, key)
+		}
+	}
+
+	key := ""
+	if query, err := ParseQueryURL(apiURL, WithQueryBaseURL(base.String()), WithStrictValidation()); err == nil {
+		if _, err := BuildQueryURL(query, WithStrictValidation()); err == nil {
+			key = base.Path + canonicalString(query)
+		}
+	}
+	if key == "" {
+		for name, list := range values {
+			if !listQueryParams[name] {
+				continue
+			}
+			for i, value := range list {
+				parts := strings.Split(value, ",")
+				sort.Strings(parts)
+				list[i] = strings.Join(parts, ",")
+			}
+		}
+		path := base.ResolveReference(parsed).Path
+		if endpoint != "" {
+			path = base.Path + endpoint
+		}
+		key = path + "?" + values.Encode()
+	}
+	key = base.Scheme + "://" + base.Host + key
+	if apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		key += "#apiKey=" + hex.EncodeToString(sum[:])
+	}
+	return key, endpoint
+}
+
+// copyNewsResp copies the articles of resp, including the source ID each one
+// points at, so callers cannot change a cached entry.
+func copyNewsResp(resp NewsResp) NewsResp {
+	if resp.Articles != nil {
+		resp.Articles = append([]Articles(nil), resp.Articles...)
+		for i, article := range resp.Articles {
+			if article.Source.ID != nil {
+				id := *article.Source.ID
+				resp.Articles[i].Source.ID = &id
+			}
+		}
+	}
+	return resp
+}
+
+func copySourcesResp(resp SourcesResp) SourcesResp {
+	if resp.Sources != nil {
+		resp.Sources = append([]Sources(nil), resp.Sources...)
+	}
+	return resp
+}
@@ -0,0 +1,158 @@
+package news_api_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingDAO struct {
+	newsCalls    int
+	sourcesCalls int
+	err          error
+}
+
+func (d *countingDAO) GetNews(apiURL string) (news_api.NewsResp, error) {
+	return d.GetNewsContext(context.Background(), apiURL)
+}
+
+func (d *countingDAO) GetSources(apiURL string) (news_api.SourcesResp, error) {
+	return d.GetSourcesContext(context.Background(), apiURL)
+}
+
+func (d *countingDAO) GetNewsContext(ctx context.Context, apiURL string) (news_api.NewsResp, error) {
+	d.newsCalls++
+	if d.err != nil {
+		return news_api.NewsResp{}, d.err
+	}
+	return news_api.NewsResp{Status: "ok", TotalResults: d.newsCalls, Articles: []news_api.Articles{{Title: apiURL, Source: news_api.NewArticleSource("bbc-news", "BBC News")}}}, nil
+}
+
+func (d *countingDAO) GetSourcesContext(ctx context.Context, apiURL string) (news_api.SourcesResp, error) {
+	d.sourcesCalls++
+	if d.err != nil {
+		return news_api.SourcesResp{}, d.err
+	}
+	return news_api.SourcesResp{Status: "ok", Sources: []news_api.Sources{{Id: apiURL}}}, nil
+}
+
+func TestCachedDAO(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("Equivalent urls share an entry", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao)
+		assert.Nil(t, err)
+		first, err := cached.GetNews("https://newsapi.org/v2/top-headlines?q=apple&country=us&sources=a%2Cb")
+		assert.Nil(t, err)
+		second, err := cached.GetNews("https://newsapi.org/v2/top-headlines?sources=b%2Ca&q=apple&country=us")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, dao.newsCalls)
+		assert.Equal(t, first, second)
+
+		_, err = cached.GetNews("https://newsapi.org/v2/top-headlines?q=pear&country=us")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, dao.newsCalls)
+	})
+
+	t.Run("Entries expire per endpoint", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao, news_api.WithCacheTTL(news_api.EndpointEverything, time.Minute))
+		assert.Nil(t, err)
+		news_api.SetCacheClock(cached, clock)
+		start := now
+		defer func() { now = start }()
+
+		cached.GetNews("https://newsapi.org/v2/everything?q=apple")
+		cached.GetSources("https://newsapi.org/v2/top-headlines/sources?country=us")
+		now = now.Add(2 * time.Minute)
+		cached.GetNews("https://newsapi.org/v2/everything?q=apple")
+		cached.GetSources("https://newsapi.org/v2/top-headlines/sources?country=us")
+		assert.Equal(t, 2, dao.newsCalls)
+		assert.Equal(t, 1, dao.sourcesCalls)
+
+		now = now.Add(24 * time.Hour)
+		cached.GetSources("https://newsapi.org/v2/top-headlines/sources?country=us")
+		assert.Equal(t, 2, dao.sourcesCalls)
+	})
+
+	t.Run("Least recently used entries are evicted", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao, news_api.WithCacheSize(2))
+		assert.Nil(t, err)
+		cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		cached.GetNews("https://newsapi.org/v2/everything?q=b")
+		cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		cached.GetNews("https://newsapi.org/v2/everything?q=c")
+		assert.Equal(t, 2, cached.Len())
+		assert.Equal(t, 3, dao.newsCalls)
+		cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		assert.Equal(t, 3, dao.newsCalls)
+		cached.GetNews("https://newsapi.org/v2/everything?q=b")
+		assert.Equal(t, 4, dao.newsCalls)
+	})
+
+	t.Run("Explicit invalidation", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao)
+		assert.Nil(t, err)
+		cached.GetNews("https://newsapi.org/v2/everything?q=a&page=1")
+		cached.GetNews("https://newsapi.org/v2/top-headlines?q=a")
+		cached.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		cached.Invalidate("https://newsapi.org/v2/everything?page=1&q=a")
+		assert.Equal(t, 2, cached.Len())
+		cached.InvalidateEndpoint(news_api.EndpointTopHeadlines)
+		assert.Equal(t, 1, cached.Len())
+		cached.Purge()
+		assert.Equal(t, 0, cached.Len())
+	})
+
+	t.Run("Errors are not cached and results are copies", func(t *testing.T) {
+		dao := &countingDAO{err: errors.New("boom")}
+		cached, err := news_api.NewCachedDAO(dao)
+		assert.Nil(t, err)
+		_, err = cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 0, cached.Len())
+
+		dao.err = nil
+		resp, _ := cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		resp.Articles[0].Title = "changed"
+		*resp.Articles[0].Source.ID = "changed"
+		resp, _ = cached.GetNews("https://newsapi.org/v2/everything?q=a")
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=a", resp.Articles[0].Title)
+		assert.Equal(t, "bbc-news", resp.Articles[0].Source.IDOrEmpty())
+	})
+
+	t.Run("Hosts and api keys do not share entries", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao)
+		assert.Nil(t, err)
+		for _, apiURL := range []string{
+			"https://newsapi.org/v2/everything?q=a",
+			"everything?q=a",
+			"http://localhost:8080/v2/everything?q=a",
+			"https://newsapi.org/v2/everything?q=a&apiKey=first",
+			"https://newsapi.org/v2/everything?apiKey=first&q=a",
+			"https://newsapi.org/v2/everything?q=a&apiKey=second",
+		} {
+			cached.GetNews(apiURL)
+		}
+		assert.Equal(t, 4, dao.newsCalls)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := news_api.NewCachedDAO(&countingDAO{}, news_api.WithCacheTTL("headlines", time.Minute))
+		assert.EqualError(t, err, "invalid endpoint")
+		_, err = news_api.NewCachedDAO(&countingDAO{}, news_api.WithCacheSize(0))
+		assert.EqualError(t, err, "cache size should be greaterthan equalto 1")
+		_, err = news_api.NewCachedDAO(nil)
+		assert.EqualError(t, err, "dao is nil")
+	})
+}
@@ -0,0 +1,99 @@
+package news_api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonicalize returns query in a canonical form built from the values
+// BuildQueryURL sends for it, so queries that send the same request compare
+// equal: list values are lowercased where NewsAPI ignores case, deduplicated
+// and sorted, q whitespace is collapsed and defaults (sortBy publishedAt, the
+// endpoint's pageSize of 100 or 20, page 1) are cleared. Query types from
+// other packages are read back through their Values.
+func Canonicalize(query Query) Query {
+	values, ok := canonicalValues(query)
+	if !ok {
+		return query
+	}
+	return queryFor(query.Endpoint(), fieldsFromValues(values, nil), nil)
+}
+
+// QueryKey returns a stable hex encoded SHA-256 of the canonical form of
+// query. Equivalent queries have the same key, for use by caches, request
+// deduplication and saved searches.
+func QueryKey(query Query) string {
+	sum := sha256.Sum256([]byte(canonicalString(query)))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalString renders the canonical form of query as endpoint?params,
+// without validating it.
+func canonicalString(query Query) string {
+	values, ok := canonicalValues(query)
+	if !ok {
+		values, _ = query.Values()
+	}
+	return query.Endpoint() + "?" + encodeQueryValues(values)
+}
+
+// canonicalValues normalises the values encode sends for query, whether or
+// not it also returns an error. It reports false for an unknown endpoint or a
+// query whose Values fail.
+func canonicalValues(query Query) (url.Values, bool) {
+	schema, ok := endpointSchemas[query.Endpoint()]
+	if !ok {
+		return nil, false
+	}
+	fields, ok := fieldsOf(query)
+	if !ok {
+		values, err := query.Values()
+		if err != nil {
+			return nil, false
+		}
+		fields = fieldsFromValues(values, nil)
+	}
+	values, _ := fields.sanitize(query.Endpoint(), nil)
+	if q := values.Get("q"); q != "" {
+		values.Set("q", strings.Join(strings.Fields(q), " "))
+	}
+	// country, category and language are already cut to the value that is
+	// sent wherever they take a single value.
+	for _, param := range []string{"searchIn", "sources", "country", "category", "domains", "excludeDomains", "language"} {
+		if value := values.Get(param); value != "" {
+			values.Set(param, strings.Join(canonicalList(strings.Split(value, ",")), ","))
+		}
+	}
+	if values.Get("sortBy") == defaultSortBy {
+		values.Del("sortBy")
+	}
+	if values.Get("pageSize") == strconv.FormatInt(schema.pageSize, 10) {
+		values.Del("pageSize")
+	}
+	if values.Get("page") == strconv.FormatInt(defaultPage, 10) {
+		values.Del("page")
+	}
+	return values, true
+}
+
+// canonicalList trims, lowercases, deduplicates and sorts list.
+func canonicalList[T ~string](list []T) []T {
+	seen := map[T]bool{}
+	canonical := []T{}
+	for _, value := range list {
+		value = T(strings.ToLower(strings.TrimSpace(string(value))))
+		if value != "" && !seen[value] {
+			seen[value] = true
+			canonical = append(canonical, value)
+		}
+	}
+	if len(canonical) == 0 {
+		return nil
+	}
+	sort.Slice(canonical, func(i, j int) bool { return canonical[i] < canonical[j] })
+	return canonical
+}
@@ -0,0 +1,144 @@
+package news_api_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+
+	t.Run("Equivalent queries have one canonical form", func(t *testing.T) {
+		query := news_api.EverythingQuery{
+			Q:        "  climate   change ",
+			SearchIn: []news_api.SearchIn{"Title", "description", "title"},
+			Sources:  []string{"reuters", "BBC-News", " "},
+			Domains:  []string{"bbc.co.uk"},
+			From:     time.Date(2024, 1, 2, 7, 0, 0, 999, est),
+			Language: "EN",
+			SortBy:   "PublishedAt",
+			PageSize: 100,
+			Page:     1,
+		}
+		assert.Equal(t, news_api.EverythingQuery{
+			Q:        "climate change",
+			SearchIn: []news_api.SearchIn{news_api.SearchInDescription, news_api.SearchInTitle},
+			Sources:  []string{"bbc-news", "reuters"},
+			Domains:  []string{"bbc.co.uk"},
+			From:     time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+			Language: news_api.LanguageEN,
+		}, news_api.Canonicalize(query))
+
+		same := news_api.EverythingQuery{
+			Q:        "climate change",
+			SearchIn: []news_api.SearchIn{news_api.SearchInDescription, news_api.SearchInTitle},
+			Sources:  []string{"reuters", "bbc-news"},
+			Domains:  []string{"bbc.co.uk"},
+			From:     time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+			Language: news_api.LanguageEN,
+		}
+		assert.Equal(t, news_api.QueryKey(query), news_api.QueryKey(same))
+		assert.Equal(t, 64, len(news_api.QueryKey(query)))
+	})
+
+	t.Run("Different queries have different keys", func(t *testing.T) {
+		base := news_api.TopHeadlinesQuery{Q: "apple", Country: "us"}
+		keys := map[string]bool{news_api.QueryKey(base): true}
+		for _, query := range []news_api.Query{
+			news_api.TopHeadlinesQuery{Q: "Apple", Country: "us"},
+			news_api.TopHeadlinesQuery{Q: "apple", Country: "gb"},
+			news_api.TopHeadlinesQuery{Q: "apple", Country: "us", Page: 2},
+			news_api.TopHeadlinesQuery{Q: "apple", Country: "us", PageSize: 100},
+			news_api.EverythingQuery{Q: "apple", Language: news_api.LanguageEN},
+			news_api.SourcesQuery{Country: "us"},
+		} {
+			keys[news_api.QueryKey(query)] = true
+		}
+		assert.Equal(t, 7, len(keys))
+		assert.Equal(t, news_api.QueryKey(base),
+			news_api.QueryKey(news_api.TopHeadlinesQuery{Q: "apple", Country: "US", PageSize: 20}))
+	})
+
+	t.Run("Single valued parameters keep the value that is sent", func(t *testing.T) {
+		enDE := rawQuery{news_api.EndpointEverything, "q=apple&language=en,de"}
+		deEN := rawQuery{news_api.EndpointEverything, "q=apple&language=de,en"}
+		assert.NotEqual(t, news_api.QueryKey(enDE), news_api.QueryKey(deEN))
+		assert.Equal(t, news_api.QueryKey(news_api.EverythingQuery{Q: "apple", Language: news_api.LanguageDE}), news_api.QueryKey(deEN))
+
+		qurl, err := news_api.ConstructQueryURL("top-headlines", map[string]interface{}{"country": []string{"xx", "gb"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?country=gb", qurl)
+		xxGB := rawQuery{news_api.EndpointTopHeadlines, "country=xx,gb"}
+		assert.Equal(t, news_api.TopHeadlinesQuery{Country: "gb"}, news_api.Canonicalize(xxGB))
+	})
+
+	t.Run("Queries that build the same URL share a key", func(t *testing.T) {
+		for _, pair := range [][2]news_api.Query{
+			{news_api.TopHeadlinesQuery{Sources: []string{"bbc-news"}, Country: "us"}, news_api.TopHeadlinesQuery{Sources: []string{"bbc-news"}}},
+			{news_api.TopHeadlinesQuery{Sources: []string{"bbc-news"}, Category: "sports"}, news_api.TopHeadlinesQuery{Sources: []string{"bbc-news"}}},
+			{news_api.TopHeadlinesQuery{Country: "us", Category: "xx"}, news_api.TopHeadlinesQuery{Country: "us"}},
+			{news_api.TopHeadlinesQuery{Country: "us", PageSize: 500}, news_api.TopHeadlinesQuery{Country: "us", PageSize: 100}},
+			{news_api.TopHeadlinesQuery{Country: "us", PageSize: -1}, news_api.TopHeadlinesQuery{Country: "us", PageSize: 100}},
+			{news_api.EverythingQuery{Q: "x", Language: "xx"}, news_api.EverythingQuery{Q: "x"}},
+			{news_api.EverythingQuery{Q: "x", SearchIn: []news_api.SearchIn{"titles"}}, news_api.EverythingQuery{Q: "x"}},
+			{news_api.EverythingQuery{Q: "x", PageSize: 101}, news_api.EverythingQuery{Q: "x", PageSize: 100}},
+			{news_api.EverythingQuery{Q: "x", Page: -2}, news_api.EverythingQuery{Q: "x", Page: 1}},
+			{news_api.EverythingQuery{Q: "x", SortBy: "newest"}, news_api.EverythingQuery{Q: "x", SortBy: news_api.SortByPublishedAt}},
+			{news_api.SourcesQuery{Country: "xx"}, news_api.SourcesQuery{}},
+		} {
+			first, err := news_api.BuildQueryURL(pair[0])
+			assert.Nil(t, err)
+			second, err := news_api.BuildQueryURL(pair[1])
+			assert.Nil(t, err)
+			assert.Equal(t, first, second)
+			assert.Equal(t, news_api.QueryKey(pair[0]), news_api.QueryKey(pair[1]), first)
+			assert.Equal(t, news_api.Canonicalize(pair[0]), news_api.Canonicalize(pair[1]), first)
+		}
+	})
+
+	t.Run("URLs in any order share a key", func(t *testing.T) {
+		first, err := news_api.ParseQueryURL("https://newsapi.org/v2/everything?sources=cnn,bbc-news&q=apple&pageSize=100&from=2024-01-02")
+		assert.Nil(t, err)
+		second, err := news_api.ParseQueryURL("https://newsapi.org/v2/everything?q=apple&from=2024-01-02T00:00:00Z&sources=bbc-news&sources=cnn&page=1")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.QueryKey(first), news_api.QueryKey(second))
+	})
+
+	t.Run("The cache keys on the canonical form", func(t *testing.T) {
+		dao := &countingDAO{}
+		cached, err := news_api.NewCachedDAO(dao)
+		assert.Nil(t, err)
+		_, err = cached.GetNews("https://newsapi.org/v2/everything?q=apple&language=EN&sortBy=publishedAt")
+		assert.Nil(t, err)
+		_, err = cached.GetNews("https://newsapi.org/v2/everything?language=en&q=apple&page=1")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, dao.newsCalls)
+		_, err = cached.GetNews("https://newsapi.org/v2/everything?language=en&q=apple&page=2")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, dao.newsCalls)
+
+		_, err = cached.GetNews("https://newsapi.org/v2/top-headlines?country=us")
+		assert.Nil(t, err)
+		_, err = cached.GetNews("https://newsapi.org/v2/top-headlines?country=us&pageSize=20")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, dao.newsCalls)
+		_, err = cached.GetNews("https://newsapi.org/v2/top-headlines?country=us&pageSize=100")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, dao.newsCalls)
+	})
+}
+
+// rawQuery is a Query from outside the package with preset parameters.
+type rawQuery struct {
+	endpoint string
+	params   string
+}
+
+func (q rawQuery) Endpoint() string { return q.endpoint }
+
+func (q rawQuery) Values() (url.Values, error) { return url.ParseQuery(q.params) }
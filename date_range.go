@@ -0,0 +1,98 @@
+package news_api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// History limits of the NewsAPI plans, for use with WithHistoryLimit.
+const (
+	DeveloperPlanHistory = 30 * 24 * time.Hour
+	BusinessPlanHistory  = 5 * 365 * 24 * time.Hour
+)
+
+// WithClock sets the clock relative from and to values such as "24h" and the
+// history limit are resolved against. The default is time.Now.
+func WithClock(now func() time.Time) QueryOption {
+	return func(o *queryOptions) {
+		if now != nil {
+			o.now = now
+		}
+	}
+}
+
+// WithHistoryLimit rejects a to date older than history and moves an older
+// from date up to the limit, or rejects it under WithStrictValidation.
+func WithHistoryLimit(history time.Duration) QueryOption {
+	return func(o *queryOptions) {
+		o.history = history
+	}
+}
+
+// parseQueryTime reads a from or to value: a timestamp or date accepted by
+// parseQueryDate, or a duration before now such as "90m", "24h", "7d" or "2w".
+// The result is in UTC.
+func parseQueryTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	if parsed, ok := parseQueryDate(trimmed, endOfDay); ok {
+		return parsed, nil
+	}
+	if ago, ok := parseAgo(trimmed); ok {
+		return now.Add(-ago).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, errors.New("not a timestamp, date or relative duration")
+}
+
+// parseQueryDate reads an RFC3339 timestamp or one of its variants, or a
+// date such as "2024-01-02". A date used as a to value means the end of that
+// day. The result is in UTC.
+func parseQueryDate(value string, endOfDay bool) (time.Time, bool) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			date = date.Add(24*time.Hour - time.Second)
+		}
+		return date, true
+	}
+	if parsed, err := ParsePublishedTime(value); err == nil {
+		return parsed.Time, true
+	}
+	return time.Time{}, false
+}
+
+// parseAgo reads a non-negative Go duration, or a whole number of days or
+// weeks such as "7d" or "2w".
+func parseAgo(value string) (time.Duration, bool) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if n := len(value); n > 1 {
+		if unit, ok := units[value[n-1:]]; ok {
+			count, err := strconv.ParseUint(value[:n-1], 10, 16)
+			return time.Duration(count) * unit, err == nil
+		}
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil || ago < 0 {
+		return 0, false
+	}
+	return ago, true
+}
+
+// limitHistory applies the history limit to the from and to dates of an
+// endpoint that accepts them. A to date before the limit is an error; an
+// earlier from date is moved up to the limit.
+func (f queryFields) limitHistory(endpoint string, oldest time.Time, d *diagnostics) (queryFields, error) {
+	if !contains(EndpointParams(endpoint), "from") {
+		return f, nil
+	}
+	if !f.To.IsZero() && f.To.Before(oldest) {
+		reason := "to date is before the history limit " + formatQueryTime(oldest)
+		d.add("to", formatQueryTime(f.To), reason)
+		return f, errors.New(reason)
+	}
+	if !f.From.IsZero() && f.From.Before(oldest) {
+		d.add("from", formatQueryTime(f.From), "before the history limit, using "+formatQueryTime(oldest))
+		f.From = oldest
+	}
+	return f, nil
+}
@@ -0,0 +1,84 @@
+package news_api_test
+
+import (
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateRange(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 30, 45, 500, time.FixedZone("EST", -5*60*60))
+	clock := news_api.WithClock(func() time.Time { return now })
+	everything := func(t *testing.T, params map[string]interface{}, opts ...news_api.QueryOption) (string, []news_api.FieldError) {
+		warnings := []news_api.FieldError{}
+		params["q"] = "apple"
+		qurl, err := news_api.ConstructQueryURL("everything", params, append([]news_api.QueryOption{clock, news_api.WithWarnings(&warnings)}, opts...)...)
+		assert.Nil(t, err)
+		return qurl, warnings
+	}
+	const base = "https://newsapi.org/v2/everything?q=apple"
+
+	t.Run("Timestamps, dates and time values", func(t *testing.T) {
+		qurl, warnings := everything(t, map[string]interface{}{"from": "2024-01-02", "to": "2024-01-05"})
+		assert.Equal(t, base+"&from=2024-01-02T00:00:00Z&to=2024-01-05T23:59:59Z", qurl)
+		assert.Equal(t, 0, len(warnings))
+
+		qurl, _ = everything(t, map[string]interface{}{"from": "2024-01-02T10:00:00+05:30", "to": "2024-01-05T08:00Z"})
+		assert.Equal(t, base+"&from=2024-01-02T04:30:00Z&to=2024-01-05T08:00:00Z", qurl)
+
+		qurl, _ = everything(t, map[string]interface{}{"from": time.Date(2024, 1, 2, 22, 0, 0, 0, time.FixedZone("PST", -8*60*60))})
+		assert.Equal(t, base+"&from=2024-01-03T06:00:00Z", qurl)
+	})
+
+	t.Run("Relative durations use the clock", func(t *testing.T) {
+		qurl, _ := everything(t, map[string]interface{}{"from": "7d", "to": "24h"})
+		assert.Equal(t, base+"&from=2024-01-08T17:30:45Z&to=2024-01-14T17:30:45Z", qurl)
+		qurl, _ = everything(t, map[string]interface{}{"from": "2w", "to": "90m"})
+		assert.Equal(t, base+"&from=2024-01-01T17:30:45Z&to=2024-01-15T16:00:45Z", qurl)
+		qurl, _ = everything(t, map[string]interface{}{"from": "0s"})
+		assert.Equal(t, base+"&from=2024-01-15T17:30:45Z", qurl)
+	})
+
+	t.Run("Malformed values are reported", func(t *testing.T) {
+		for _, value := range []string{"yesterday", "-24h", "7x", "d", "2024-13-01"} {
+			qurl, warnings := everything(t, map[string]interface{}{"from": value})
+			assert.Equal(t, base, qurl)
+			assert.Equal(t, []news_api.FieldError{{Field: "from", Value: value, Reason: "not a timestamp, date or relative duration"}}, warnings)
+		}
+		qurl, warnings := everything(t, map[string]interface{}{"from": "1d", "to": "7d"})
+		assert.Equal(t, base, qurl)
+		assert.Equal(t, "to date is before from date 2024-01-14T17:30:45Z", warnings[0].Reason)
+	})
+
+	t.Run("History limit clamps from", func(t *testing.T) {
+		limit := news_api.WithHistoryLimit(news_api.DeveloperPlanHistory)
+		qurl, warnings := everything(t, map[string]interface{}{"from": "2023-11-01", "to": "2024-01-10"}, limit)
+		assert.Equal(t, base+"&from=2023-12-16T17:30:45Z&to=2024-01-10T23:59:59Z", qurl)
+		assert.Equal(t, []news_api.FieldError{{Field: "from", Value: "2023-11-01T00:00:00Z",
+			Reason: "before the history limit, using 2023-12-16T17:30:45Z"}}, warnings)
+
+		qurl, warnings = everything(t, map[string]interface{}{"from": "30d"}, limit)
+		assert.Equal(t, base+"&from=2023-12-16T17:30:45Z", qurl)
+		assert.Equal(t, 0, len(warnings))
+
+		qurl, err := news_api.ConstructQueryURL("sources", map[string]interface{}{"from": "2020-01-01"}, clock, limit)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines/sources?", qurl)
+	})
+
+	t.Run("History limit rejects", func(t *testing.T) {
+		limit := news_api.WithHistoryLimit(news_api.DeveloperPlanHistory)
+		_, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "from": "60d"},
+			clock, limit, news_api.WithStrictValidation())
+		assert.EqualError(t, err, `invalid query: from: before the history limit, using 2023-12-16T17:30:45Z (got "2023-11-16T17:30:45Z")`)
+
+		_, err = news_api.BuildQueryURL(news_api.EverythingQuery{Q: "apple", To: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)}, clock, limit)
+		assert.EqualError(t, err, "to date is before the history limit 2023-12-16T17:30:45Z")
+
+		_, err = news_api.BuildQueryURL(news_api.EverythingQuery{Q: "apple", To: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)})
+		assert.Nil(t, err)
+	})
+}
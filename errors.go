@@ -0,0 +1,154 @@
+package news_api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Error codes returned by NewsAPI in the code field of an error response.
+const (
+	CodeAPIKeyDisabled        = "apiKeyDisabled"
+	CodeAPIKeyExhausted       = "apiKeyExhausted"
+	CodeAPIKeyInvalid         = "apiKeyInvalid"
+	CodeAPIKeyMissing         = "apiKeyMissing"
+	CodeParameterInvalid      = "parameterInvalid"
+	CodeParametersMissing     = "parametersMissing"
+	CodeRateLimited           = "rateLimited"
+	CodeSourcesTooMany        = "sourcesTooMany"
+	CodeSourceDoesNotExist    = "sourceDoesNotExist"
+	CodeMaximumResultsReached = "maximumResultsReached"
+	CodeUnexpectedError       = "unexpectedError"
+)
+
+// APIError is returned by GetNews and GetSources when NewsAPI answers with
+// status "error". URL is the request URL with any API key redacted.
+type APIError struct {
+	Code       string
+	Message    string
+	HTTPStatus int
+	URL        string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Message
+}
+
+// Is reports whether target is an *APIError with the same Code, which lets the
+// Err* sentinels below be used with errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return t.Code != "" && t.Code == e.Code
+}
+
+var (
+	ErrAPIKeyDisabled        = &APIError{Code: CodeAPIKeyDisabled, Message: "api key disabled"}
+	ErrAPIKeyExhausted       = &APIError{Code: CodeAPIKeyExhausted, Message: "api key has no more requests available"}
+	ErrAPIKeyInvalid         = &APIError{Code: CodeAPIKeyInvalid, Message: "api key invalid"}
+	ErrAPIKeyMissing         = &APIError{Code: CodeAPIKeyMissing, Message: "api key missing"}
+	ErrParameterInvalid      = &APIError{Code: CodeParameterInvalid, Message: "parameter invalid"}
+	ErrParametersMissing     = &APIError{Code: CodeParametersMissing, Message: "required parameters missing"}
+	ErrRateLimited           = &APIError{Code: CodeRateLimited, Message: "rate limited"}
+	ErrSourcesTooMany        = &APIError{Code: CodeSourcesTooMany, Message: "too many sources"}
+	ErrSourceDoesNotExist    = &APIError{Code: CodeSourceDoesNotExist, Message: "source does not exist"}
+	ErrMaximumResultsReached = &APIError{Code: CodeMaximumResultsReached, Message: "maximum results reached"}
+	ErrUnexpectedError       = &APIError{Code: CodeUnexpectedError, Message: "unexpected error"}
+)
+
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+func IsAPIKeyInvalid(err error) bool {
+	return errors.Is(err, ErrAPIKeyInvalid)
+}
+
+func IsMaximumResultsReached(err error) bool {
+	return errors.Is(err, ErrMaximumResultsReached)
+}
+
+func IsParameterInvalid(err error) bool {
+	return errors.Is(err, ErrParameterInvalid)
+}
+
+func newAPIError(code, message string, resp *apiResponse) *APIError {
+	return &APIError{
+		Code:       code,
+		Message:    message,
+		HTTPStatus: resp.statusCode,
+		URL:        RedactURL(resp.url),
+	}
+}
+
+// ResponseError is returned when a response cannot be read as a NewsAPI
+// payload, such as an HTML error page from a proxy, an empty 429 or a JSON
+// body with a non-2xx status. Body holds the start of the response body.
+type ResponseError struct {
+	Reason      string
+	StatusCode  int
+	ContentType string
+	URL         string
+	Body        string
+}
+
+func (e *ResponseError) Error() string {
+	body := e.Body
+	if body == "" {
+		body = "<empty>"
+	}
+	return fmt.Sprintf("%s: status %d, content type %q, url %s, body: %s", e.Reason, e.StatusCode, e.ContentType, e.URL, body)
+}
+
+const (
+	maxResponseBytes = 10 << 20
+	maxSnippetBytes  = 512
+)
+
+func newResponseError(resp *apiResponse, reason string) *ResponseError {
+	return &ResponseError{
+		Reason:      reason,
+		StatusCode:  resp.statusCode,
+		ContentType: resp.header.Get("Content-Type"),
+		URL:         RedactURL(resp.url),
+		Body:        bodySnippet(resp.body),
+	}
+}
+
+// bodySnippet returns the first maxSnippetBytes of body on a single line.
+func bodySnippet(body []byte) string {
+	if len(body) > maxSnippetBytes {
+		body = body[:maxSnippetBytes]
+	}
+	return strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "")), " ")
+}
+
+const redacted = "REDACTED"
+
+// RedactURL replaces the value of any apiKey query parameter of rawURL with
+// REDACTED, so the URL can be logged or stored.
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := parsed.Query()
+	changed := false
+	for key := range query {
+		if strings.EqualFold(key, "apiKey") {
+			query[key] = []string{redacted}
+			changed = true
+		}
+	}
+	if !changed {
+		return rawURL
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
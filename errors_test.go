@@ -0,0 +1,153 @@
+package news_api_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		code   string
+	}{
+		"/v2/everything":            {http.StatusUnauthorized, news_api.CodeAPIKeyInvalid},
+		"/v2/top-headlines":         {http.StatusTooManyRequests, news_api.CodeRateLimited},
+		"/v2/top-headlines/sources": {http.StatusBadRequest, news_api.CodeParameterInvalid},
+		"/v2/everything/paged":      {http.StatusUpgradeRequired, news_api.CodeMaximumResultsReached},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[r.URL.Path]
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		fmt.Fprintf(w, `{"status":"error","code":%q,"message":"message for %s"}`, resp.code, resp.code)
+	}))
+	defer server.Close()
+
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL))
+	assert.Nil(t, err)
+
+	t.Run("Error responses carry code, message, status and url", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple&apiKey=secret")
+		assert.EqualError(t, err, "message for apiKeyInvalid")
+		var apiErr *news_api.APIError
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, news_api.CodeAPIKeyInvalid, apiErr.Code)
+		assert.Equal(t, "message for apiKeyInvalid", apiErr.Message)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatus)
+		assert.Equal(t, false, strings.Contains(apiErr.URL, "secret"))
+		assert.Equal(t, true, strings.Contains(apiErr.URL, "apiKey=REDACTED"))
+		assert.Equal(t, true, strings.HasPrefix(apiErr.URL, server.URL+"/v2/everything?"))
+		assert.Equal(t, true, news_api.IsAPIKeyInvalid(err))
+		assert.Equal(t, false, news_api.IsRateLimited(err))
+	})
+
+	t.Run("Sentinel checks", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/top-headlines?q=apple")
+		assert.Equal(t, true, news_api.IsRateLimited(err))
+		assert.Equal(t, true, errors.Is(err, news_api.ErrRateLimited))
+
+		_, err = newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources?country=us")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+		assert.Equal(t, false, errors.Is(err, news_api.ErrParametersMissing))
+
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything/paged?q=apple&page=2")
+		assert.Equal(t, true, news_api.IsMaximumResultsReached(fmt.Errorf("fetching page 2: %w", err)))
+	})
+
+	t.Run("Other errors are not api errors", func(t *testing.T) {
+		assert.Equal(t, false, news_api.IsRateLimited(errors.New("rateLimited")))
+		assert.Equal(t, false, news_api.IsAPIKeyInvalid(nil))
+	})
+
+	t.Run("Redacted urls", func(t *testing.T) {
+		assert.Equal(t, "https://newsapi.org/v2/everything?apikey=REDACTED&q=apple",
+			news_api.RedactURL("https://newsapi.org/v2/everything?q=apple&apikey=secret"))
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple", news_api.RedactURL("https://newsapi.org/v2/everything?q=apple"))
+	})
+}
+
+func TestResponseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/bad-gateway":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>\n<body>502 Bad Gateway</body>\n</html>"))
+		case "/v2/empty":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/v2/broken":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"ok","articles":[`))
+		case "/v2/huge":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", 2000)))
+		case "/v2/unavailable":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"ok"}`))
+		default:
+			w.Write([]byte(`{"status":"ok","sources":[{"id":"abc"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL))
+	assert.Nil(t, err)
+
+	t.Run("Html error page", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/bad-gateway?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, "response is not json", respErr.Reason)
+		assert.Equal(t, http.StatusBadGateway, respErr.StatusCode)
+		assert.Equal(t, "text/html", respErr.ContentType)
+		assert.Equal(t, "<html> <body>502 Bad Gateway</body> </html>", respErr.Body)
+		assert.Equal(t, true, strings.Contains(err.Error(), "502 Bad Gateway"))
+	})
+
+	t.Run("Empty body", func(t *testing.T) {
+		_, err := newsAPI.GetSources("https://newsapi.org/v2/empty")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, "empty response body", respErr.Reason)
+		assert.Equal(t, http.StatusTooManyRequests, respErr.StatusCode)
+		assert.Equal(t, true, strings.Contains(err.Error(), "<empty>"))
+	})
+
+	t.Run("Truncated json", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/broken?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, true, strings.HasPrefix(respErr.Reason, "invalid json"))
+		assert.Equal(t, `{"status":"ok","articles":[`, respErr.Body)
+	})
+
+	t.Run("Snippet is bounded", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/huge?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, 512, len(respErr.Body))
+	})
+
+	t.Run("Json with non 2xx status", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/unavailable?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, "unexpected status code", respErr.Reason)
+		assert.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+	})
+
+	t.Run("Json served as text/plain", func(t *testing.T) {
+		resp, err := newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", resp.Sources[0].Id)
+	})
+}
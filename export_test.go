@@ -0,0 +1,14 @@
+package news_api
+
+import "time"
+
+// SetClock replaces the clock used by the rate limiter and daily quota of a
+// client returned by InitializeNewsAPI.
+func SetClock(dao NewsAPIDAO, now func() time.Time) {
+	dao.(*newsAPI).limiter.now = now
+}
+
+// SetCacheClock replaces the clock used to expire entries of c.
+func SetCacheClock(c *CachedDAO, now func() time.Time) {
+	c.now = now
+}
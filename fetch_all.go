@@ -0,0 +1,166 @@
+package news_api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FetchOptions configures FetchAll.
+type FetchOptions struct {
+	// Workers is the number of pages fetched in parallel. The default is 4.
+	Workers int
+	// MaxPages caps the number of pages fetched, including the first one.
+	// 0 means no cap.
+	MaxPages int
+}
+
+const defaultFetchWorkers = 4
+
+// FetchError is returned by FetchAll when some pages could not be fetched.
+// Pages maps each failed page number to its error.
+type FetchError struct {
+	Pages map[int]error
+}
+
+func (e *FetchError) Error() string {
+	pages := e.pageNumbers()
+	messages := make([]string, 0, len(pages))
+	for _, page := range pages {
+		messages = append(messages, fmt.Sprintf("page %d: %s", page, e.Pages[page].Error()))
+	}
+	return fmt.Sprintf("fetching %d pages failed: %s", len(pages), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the page errors in page order so errors.Is and errors.As
+// can look at each of them.
+func (e *FetchError) Unwrap() []error {
+	errs := []error{}
+	for _, page := range e.pageNumbers() {
+		errs = append(errs, e.Pages[page])
+	}
+	return errs
+}
+
+func (e *FetchError) pageNumbers() []int {
+	pages := make([]int, 0, len(e.Pages))
+	for page := range e.Pages {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+// FetchAll fetches the first page of apiURL and then, based on its
+// TotalResults, the remaining pages in parallel. Articles are returned in
+// page order. When some pages fail, the articles of the other pages are
+// returned together with a *FetchError. Pages refused with
+// maximumResultsReached mark the end of the results and are not errors; no
+// later page is requested once one is refused.
+//
+// Every request goes through dao, so a client created with WithRateLimit or
+// WithDailyQuota applies its limits to all workers.
+func FetchAll(ctx context.Context, dao NewsAPIDAO, apiURL string, opts FetchOptions) ([]Articles, error) {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	firstPage := int(defaultPage)
+	if page, err := strconv.Atoi(parsed.Query().Get("page")); err == nil && page > 0 {
+		firstPage = page
+	}
+	first, err := dao.GetNewsContext(ctx, withPage(parsed, firstPage))
+	if err != nil {
+		if IsMaximumResultsReached(err) {
+			return []Articles{}, nil
+		}
+		return nil, err
+	}
+
+	pageSize := len(first.Articles)
+	if size, err := strconv.Atoi(parsed.Query().Get("pageSize")); err == nil && size > 0 {
+		pageSize = size
+	}
+	lastPage := firstPage
+	if pageSize > 0 && len(first.Articles) > 0 {
+		lastPage = (first.TotalResults + pageSize - 1) / pageSize
+	}
+	if opts.MaxPages > 0 && lastPage > firstPage+opts.MaxPages-1 {
+		lastPage = firstPage + opts.MaxPages - 1
+	}
+	if lastPage <= firstPage {
+		return first.Articles, nil
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = defaultFetchWorkers
+	}
+	var (
+		results = make([][]Articles, lastPage-firstPage+1)
+		failed  = map[int]error{}
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		pages   = make(chan int)
+		// limitPage is the first page refused with maximumResultsReached.
+		// No page after it is requested once it is known.
+		limitPage = 0
+	)
+	pastLimit := func(page int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return limitPage > 0 && page > limitPage
+	}
+	results[0] = first.Articles
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				if pastLimit(page) {
+					continue
+				}
+				resp, err := dao.GetNewsContext(ctx, withPage(parsed, page))
+				mu.Lock()
+				switch {
+				case err == nil:
+					results[page-firstPage] = resp.Articles
+				case IsMaximumResultsReached(err):
+					if limitPage == 0 || page < limitPage {
+						limitPage = page
+					}
+				default:
+					failed[page] = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for page := firstPage + 1; page <= lastPage; page++ {
+		if pastLimit(page) {
+			break
+		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			failed[page] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		pages <- page
+	}
+	close(pages)
+	wg.Wait()
+
+	articles := []Articles{}
+	for _, pageArticles := range results {
+		articles = append(articles, pageArticles...)
+	}
+	if len(failed) > 0 {
+		return articles, &FetchError{Pages: failed}
+	}
+	return articles, nil
+}
@@ -0,0 +1,102 @@
+package news_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchAll(t *testing.T) {
+	var calls int32
+	server := pagingServer(95, 0, &calls)
+	defer server.Close()
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL))
+	assert.Nil(t, err)
+
+	t.Run("Fetches every page in page order", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		articles, err := news_api.FetchAll(context.Background(), newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", news_api.FetchOptions{Workers: 3})
+		assert.Nil(t, err)
+		assert.Equal(t, 95, len(articles))
+		for i, article := range articles {
+			assert.Equal(t, fmt.Sprintf("article %d", i), article.Title)
+		}
+		assert.Equal(t, int32(10), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Honors the page cap", func(t *testing.T) {
+		articles, err := news_api.FetchAll(context.Background(), newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10&page=3", news_api.FetchOptions{MaxPages: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 20, len(articles))
+		assert.Equal(t, "article 20", articles[0].Title)
+	})
+
+	t.Run("Maximum results reached ends the results", func(t *testing.T) {
+		var limitedCalls int32
+		limited := pagingServer(500, 30, &limitedCalls)
+		defer limited.Close()
+		limitedAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(limited.URL))
+		assert.Nil(t, err)
+		articles, err := news_api.FetchAll(context.Background(), limitedAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", news_api.FetchOptions{Workers: 8})
+		assert.Nil(t, err)
+		assert.Equal(t, 30, len(articles))
+		// Each worker stops after its first refused page: at most the first
+		// page, the two other useful pages and one refused page per worker.
+		assert.LessOrEqual(t, atomic.LoadInt32(&limitedCalls), int32(1+2+8))
+
+		atomic.StoreInt32(&limitedCalls, 0)
+		articles, err = news_api.FetchAll(context.Background(), limitedAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", news_api.FetchOptions{Workers: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, 30, len(articles))
+		assert.Equal(t, int32(4), atomic.LoadInt32(&limitedCalls))
+	})
+
+	t.Run("Partial results and aggregated error", func(t *testing.T) {
+		flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			w.Header().Set("Content-Type", "application/json")
+			if page == 2 || page == 4 {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"status":"error","code":"unexpectedError","message":"boom"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(news_api.NewsResp{Status: "ok", TotalResults: 5, Articles: []news_api.Articles{{Title: strconv.Itoa(page)}}})
+		}))
+		defer flaky.Close()
+		flakyAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(flaky.URL))
+		assert.Nil(t, err)
+		articles, err := news_api.FetchAll(context.Background(), flakyAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=1", news_api.FetchOptions{})
+		assert.Equal(t, []news_api.Articles{{Title: "1"}, {Title: "3"}, {Title: "5"}}, articles)
+		var fetchErr *news_api.FetchError
+		assert.Equal(t, true, errors.As(err, &fetchErr))
+		assert.Equal(t, 2, len(fetchErr.Pages))
+		assert.Equal(t, true, errors.Is(err, news_api.ErrUnexpectedError))
+		assert.Equal(t, "fetching 2 pages failed: page 2: boom; page 4: boom", err.Error())
+	})
+
+	t.Run("First page errors are returned as is", func(t *testing.T) {
+		_, err := news_api.FetchAll(context.Background(), newsAPI, "://bad", news_api.FetchOptions{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Workers share the client rate limiter", func(t *testing.T) {
+		limitedAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithRateLimit(50, 1))
+		assert.Nil(t, err)
+		started := time.Now()
+		articles, err := news_api.FetchAll(context.Background(), limitedAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=20", news_api.FetchOptions{Workers: 5})
+		assert.Nil(t, err)
+		assert.Equal(t, 95, len(articles))
+		assert.Equal(t, true, time.Since(started) >= 70*time.Millisecond)
+	})
+}
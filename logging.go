@@ -0,0 +1,84 @@
+package news_api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// WithLogger sends structured events about requests, responses, retries and
+// rate limiting to logger: debug for every request and response, warn for
+// failed attempts and info for retries. URLs and error messages are logged
+// with the API key replaced by REDACTED. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(rep *newsAPI) error {
+		if logger == nil {
+			return errors.New("logger is nil")
+		}
+		rep.logger = logger
+		return nil
+	}
+}
+
+// WithQueryLogger logs every parameter ConstructQueryURL or BuildQueryURL
+// drops, adjusts or rejects at warn level and the URL it builds at debug
+// level.
+func WithQueryLogger(logger *slog.Logger) QueryOption {
+	return func(o *queryOptions) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithCacheLogger logs cache hits, misses and evictions at debug level.
+func WithCacheLogger(logger *slog.Logger) CacheOption {
+	return func(c *CachedDAO) error {
+		if logger == nil {
+			return errors.New("logger is nil")
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// discardLogger is used when no logger is configured.
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// redactSecrets replaces the API key, and any apiKey parameter of u, in text.
+func redactSecrets(text, apikey string, u *url.URL) string {
+	secrets := []string{apikey}
+	if u != nil {
+		for key, values := range u.Query() {
+			if strings.EqualFold(key, "apiKey") {
+				secrets = append(secrets, values...)
+			}
+		}
+	}
+	for _, secret := range secrets {
+		if strings.TrimSpace(secret) != "" {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+	}
+	return text
+}
+
+func logQuery(ctx context.Context, logger *slog.Logger, endpoint string, fields []FieldError, strict bool) {
+	message := "newsapi query parameter adjusted"
+	if strict {
+		message = "newsapi query parameter rejected"
+	}
+	for _, field := range fields {
+		logger.LogAttrs(ctx, slog.LevelWarn, message, slog.String("endpoint", endpoint),
+			slog.String("field", field.Field), slog.String("value", field.Value), slog.String("reason", field.Reason))
+	}
+}
@@ -0,0 +1,113 @@
+package news_api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logEvents decodes the JSON lines written by a slog.JSONHandler.
+func logEvents(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	events := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		event := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func messages(events []map[string]interface{}) []string {
+	list := []string{}
+	for _, event := range events {
+		list = append(list, event["msg"].(string))
+	}
+	return list
+}
+
+func TestLogging(t *testing.T) {
+	const apiKey = "secret-key-0123456789"
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"error","code":"unexpectedError","message":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","totalResults":1,"articles":[{"title":"Apple"}]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	t.Run("Requests and retries", func(t *testing.T) {
+		buf.Reset()
+		newsAPI, err := news_api.InitializeNewsAPI(apiKey, news_api.WithBaseURL(server.URL), news_api.WithLogger(logger),
+			news_api.WithRetryPolicy(news_api.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple&apiKey=" + apiKey)
+		assert.Nil(t, err)
+
+		events := logEvents(t, &buf)
+		assert.Equal(t, []string{"newsapi request", "newsapi request failed", "newsapi retry", "newsapi request", "newsapi response"}, messages(events))
+		assert.Equal(t, float64(503), events[1]["status"])
+		assert.Equal(t, "try again", events[1]["error"])
+		assert.Equal(t, float64(2), events[2]["attempt"])
+		assert.Equal(t, true, strings.Contains(events[4]["url"].(string), "apiKey=REDACTED"))
+		assert.Equal(t, false, strings.Contains(buf.String(), apiKey))
+	})
+
+	t.Run("Network errors are redacted", func(t *testing.T) {
+		buf.Reset()
+		newsAPI, err := news_api.InitializeNewsAPI(apiKey, news_api.WithLogger(logger))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews("http://127.0.0.1:1/v2/everything?q=apple&apiKey=" + apiKey)
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"newsapi request", "newsapi request failed"}, messages(logEvents(t, &buf)))
+		assert.Equal(t, false, strings.Contains(buf.String(), apiKey))
+	})
+
+	t.Run("Query construction", func(t *testing.T) {
+		buf.Reset()
+		_, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "pageSize": 500}, news_api.WithQueryLogger(logger))
+		assert.Nil(t, err)
+		events := logEvents(t, &buf)
+		assert.Equal(t, []string{"newsapi query parameter adjusted", "newsapi query url built"}, messages(events))
+		assert.Equal(t, "pageSize", events[0]["field"])
+		assert.Equal(t, "WARN", events[0]["level"])
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&pageSize=100", events[1]["url"])
+	})
+
+	t.Run("Cache hits", func(t *testing.T) {
+		buf.Reset()
+		cached, err := news_api.NewCachedDAO(&countingDAO{}, news_api.WithCacheLogger(logger), news_api.WithCacheSize(1))
+		assert.Nil(t, err)
+		for _, q := range []string{"apple", "apple", "google"} {
+			_, err = cached.GetNews("https://newsapi.org/v2/everything?q=" + q)
+			assert.Nil(t, err)
+		}
+		events := logEvents(t, &buf)
+		assert.Equal(t, []string{"newsapi cache miss", "newsapi cache hit", "newsapi cache miss", "newsapi cache eviction"}, messages(events))
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple", events[1]["key"])
+	})
+
+	t.Run("Nil logger", func(t *testing.T) {
+		_, err := news_api.InitializeNewsAPI(apiKey, news_api.WithLogger(nil))
+		assert.EqualError(t, err, "logger is nil")
+	})
+}
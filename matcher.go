@@ -0,0 +1,162 @@
+package news_api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Matcher evaluates a q expression against articles locally, so stored or
+// cached articles can be filtered the way NewsAPI filters a live query.
+// Terms and phrases match case insensitively as whole words in the searched
+// fields; NOT and - exclude and + is treated as a plain term.
+type Matcher struct {
+	expr   Expr
+	fields []SearchIn
+}
+
+// CompileQ parses q and returns a Matcher over the searchIn fields, or over
+// title, description and content when none are given. A blank q matches
+// every article.
+func CompileQ(q string, searchIn ...SearchIn) (*Matcher, error) {
+	if len(q) > int(maxQstringLength) {
+		return nil, errors.New("query string length should be lessthan equalto 500")
+	}
+	var expr Expr
+	if strings.TrimSpace(q) != "" {
+		var err error
+		if expr, err = ParseQ(q); err != nil {
+			return nil, err
+		}
+	}
+	return NewMatcher(expr, searchIn...)
+}
+
+// NewMatcher returns a Matcher for an expression built with the q builder.
+// A nil expr matches every article.
+func NewMatcher(expr Expr, searchIn ...SearchIn) (*Matcher, error) {
+	for _, field := range searchIn {
+		if !contains(allowedSearchIn, string(field)) {
+			return nil, fmt.Errorf("searchIn %q should be one of %s", field, strings.Join(allowedSearchIn, ", "))
+		}
+	}
+	if len(searchIn) == 0 {
+		searchIn = []SearchIn{SearchInTitle, SearchInDescription, SearchInContent}
+	}
+	return &Matcher{expr: expr, fields: searchIn}, nil
+}
+
+// Match reports whether article satisfies the expression.
+func (m *Matcher) Match(article Articles) bool {
+	_, ok := m.Score(article)
+	return ok
+}
+
+// Score reports whether article satisfies the expression and how many times
+// the terms it was matched on occur in the searched fields.
+func (m *Matcher) Score(article Articles) (int, bool) {
+	if m.expr == nil {
+		return 0, true
+	}
+	return evaluate(m.expr, m.text(article))
+}
+
+// Filter returns the articles that satisfy the expression, in order.
+func (m *Matcher) Filter(articles []Articles) []Articles {
+	matched := []Articles{}
+	for _, article := range articles {
+		if m.Match(article) {
+			matched = append(matched, article)
+		}
+	}
+	return matched
+}
+
+func (m *Matcher) text(article Articles) string {
+	parts := []string{}
+	for _, field := range m.fields {
+		value := ""
+		switch field {
+		case SearchInTitle:
+			value = article.Title
+		case SearchInDescription:
+			value = article.Description
+		case SearchInContent:
+			value = article.Content
+		}
+		parts = append(parts, strings.Join(strings.Fields(value), " "))
+	}
+	// Fields are joined with a newline so phrases never match across them.
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+// evaluate reports whether expr holds for the normalized text, and the
+// number of occurrences of the terms that made it hold.
+func evaluate(expr Expr, text string) (int, bool) {
+	switch e := expr.(type) {
+	case TermExpr:
+		count := countWords(text, strings.ToLower(strings.TrimSpace(e.Word)))
+		return count, count > 0
+	case PhraseExpr:
+		count := countWords(text, strings.ToLower(strings.Join(strings.Fields(e.Text), " ")))
+		return count, count > 0
+	case AndExpr:
+		score := 0
+		for _, operand := range e.Exprs {
+			count, ok := evaluate(operand, text)
+			if !ok {
+				return 0, false
+			}
+			score += count
+		}
+		return score, len(e.Exprs) > 0
+	case OrExpr:
+		score, matched := 0, false
+		for _, operand := range e.Exprs {
+			if count, ok := evaluate(operand, text); ok {
+				score += count
+				matched = true
+			}
+		}
+		return score, matched
+	case NotExpr:
+		_, ok := evaluate(e.Expr, text)
+		return 0, !ok
+	case MustExpr:
+		return evaluate(e.Expr, text)
+	case ExcludeExpr:
+		_, ok := evaluate(e.Expr, text)
+		return 0, !ok
+	}
+	return 0, false
+}
+
+// countWords counts the occurrences of word in text that are not part of a
+// longer word.
+func countWords(text, word string) int {
+	if word == "" {
+		return 0
+	}
+	count := 0
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], word)
+		if index < 0 {
+			break
+		}
+		start := offset + index
+		end := start + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			count++
+		}
+		offset = start + 1
+	}
+	return count
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
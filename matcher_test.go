@@ -0,0 +1,85 @@
+package news_api_test
+
+import (
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	articles := []news_api.Articles{
+		{Title: "Climate change summit opens", Description: "Leaders meet on policy.", Content: "Bitcoin is not discussed."},
+		{Title: "Opinion: climate   CHANGE is here", Description: "A column.", Content: "Climate change, again."},
+		{Title: "Bitcoin price", Description: "Crypto markets climate", Content: "Change is coming to Ethereum."},
+		{Title: "Apple earnings", Description: "iPhone sales rise.", Content: "Pineapple prices fall."},
+	}
+	filter := func(q string, searchIn ...news_api.SearchIn) []string {
+		matcher, err := news_api.CompileQ(q, searchIn...)
+		assert.Nil(t, err, q)
+		titles := []string{}
+		for _, article := range matcher.Filter(articles) {
+			titles = append(titles, article.Title)
+		}
+		return titles
+	}
+
+	t.Run("Terms and phrases match whole words", func(t *testing.T) {
+		assert.Equal(t, []string{"Apple earnings"}, filter("apple"))
+		assert.Equal(t, []string{"Climate change summit opens", "Opinion: climate   CHANGE is here"}, filter(`"climate change"`))
+		assert.Equal(t, []string{"Climate change summit opens", "Opinion: climate   CHANGE is here", "Bitcoin price"},
+			filter("climate change"))
+		assert.Equal(t, []string{}, filter("pine"))
+	})
+
+	t.Run("Boolean operators", func(t *testing.T) {
+		assert.Equal(t, []string{"Climate change summit opens"}, filter(`"climate change" -opinion`))
+		assert.Equal(t, []string{"Climate change summit opens"}, filter(`"climate change" NOT opinion`))
+		assert.Equal(t, []string{"Bitcoin price", "Apple earnings"}, filter("iphone OR ethereum"))
+		assert.Equal(t, []string{"Climate change summit opens", "Apple earnings"}, filter("apple OR policy AND +leaders"))
+		assert.Equal(t, []string{"Apple earnings"}, filter("apple AND NOT (bitcoin OR climate)"))
+		assert.Equal(t, 4, len(filter("   ")))
+	})
+
+	t.Run("searchIn limits the fields", func(t *testing.T) {
+		assert.Equal(t, []string{"Climate change summit opens", "Bitcoin price"}, filter("bitcoin"))
+		assert.Equal(t, []string{"Bitcoin price"}, filter("bitcoin", news_api.SearchInTitle))
+		assert.Equal(t, []string{"Climate change summit opens"}, filter("bitcoin", news_api.SearchInContent))
+		assert.Equal(t, []string{"Opinion: climate   CHANGE is here"}, filter(`"climate change"`, news_api.SearchInContent))
+	})
+
+	t.Run("Phrases do not span fields", func(t *testing.T) {
+		assert.Equal(t, []string{"Opinion: climate   CHANGE is here"},
+			filter(`"climate change"`, news_api.SearchInDescription, news_api.SearchInContent))
+		assert.Equal(t, []string{"Opinion: climate   CHANGE is here", "Bitcoin price"}, filter("climate change", news_api.SearchInDescription, news_api.SearchInContent))
+	})
+
+	t.Run("Score counts matched terms", func(t *testing.T) {
+		matcher, err := news_api.CompileQ(`climate OR bitcoin`)
+		assert.Nil(t, err)
+		score, ok := matcher.Score(articles[1])
+		assert.True(t, ok)
+		assert.Equal(t, 2, score)
+		score, ok = matcher.Score(articles[3])
+		assert.False(t, ok)
+		assert.Equal(t, 0, score)
+	})
+
+	t.Run("Expressions from the builder", func(t *testing.T) {
+		matcher, err := news_api.NewMatcher(news_api.And(news_api.Phrase("climate change"), news_api.Exclude(news_api.Term("opinion"))))
+		assert.Nil(t, err)
+		assert.True(t, matcher.Match(articles[0]))
+		assert.False(t, matcher.Match(articles[1]))
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		_, err := news_api.CompileQ("(apple")
+		assert.EqualError(t, err, "unbalanced parentheses in query")
+		_, err = news_api.CompileQ(strings.Repeat("a", 501))
+		assert.EqualError(t, err, "query string length should be lessthan equalto 500")
+		_, err = news_api.CompileQ("apple", news_api.SearchIn("body"))
+		assert.EqualError(t, err, `searchIn "body" should be one of title, description, content`)
+	})
+}
@@ -1,13 +1,17 @@
 package news_api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,17 +19,19 @@ import (
 type NewsAPIDAO interface {
 	GetNews(apiURL string) (NewsResp, error)
 	GetSources(apiURL string) (SourcesResp, error)
+	GetNewsContext(ctx context.Context, apiURL string) (NewsResp, error)
+	GetSourcesContext(ctx context.Context, apiURL string) (SourcesResp, error)
 }
 
 type Articles struct {
-	Source      interface{} `json:"source,omitempty"`
-	Author      string      `json:"author,omitempty"`
-	Title       string      `json:"title,omitempty"`
-	Description string      `json:"description,omitempty"`
-	Url         string      `json:"url,omitempty"`
-	UrlToImage  string      `json:"urlToImage,omitempty"`
-	PublishedAt string      `json:"publishedAt,omitempty"`
-	Content     string      `json:"content,omitempty"`
+	Source      ArticleSource `json:"source,omitempty"`
+	Author      string        `json:"author,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Url         string        `json:"url,omitempty"`
+	UrlToImage  string        `json:"urlToImage,omitempty"`
+	PublishedAt string        `json:"publishedAt,omitempty"`
+	Content     string        `json:"content,omitempty"`
 }
 
 type Sources struct {
@@ -54,17 +60,35 @@ type SourcesResp struct {
 }
 
 type newsAPI struct {
-	apikey string
+	apikey      string
+	httpClient  *http.Client
+	baseURL     string
+	userAgent   string
+	transport   http.RoundTripper
+	timeout     *time.Duration
+	retryPolicy RetryPolicy
+	limiter     limiter
+	logger      *slog.Logger
 }
 
-func InitializeNewsAPI(apikey string) (NewsAPIDAO, error) {
+func InitializeNewsAPI(apikey string, opts ...Option) (NewsAPIDAO, error) {
 	trimStr := strings.TrimSpace(apikey)
 	if len(trimStr) == 0 {
 		return nil, errors.New("invalid api key")
 	}
-	return &newsAPI{
-		apikey: apikey,
-	}, nil
+	rep := &newsAPI{
+		apikey:  apikey,
+		baseURL: defaultBaseURL,
+		limiter: limiter{now: time.Now},
+		logger:  discardLogger,
+	}
+	for _, opt := range opts {
+		if err := opt(rep); err != nil {
+			return nil, err
+		}
+	}
+	rep.httpClient = rep.buildHTTPClient()
+	return rep, nil
 }
 
 const (
@@ -73,9 +97,6 @@ const (
 	defaultPage      = int64(1)
 	defaultSortBy    = "publishedAt"
 	maxQstringLength = int64(500)
-	everyThingUrl    = "https://newsapi.org/v2/everything?"
-	topHeadlinesUrl  = "https://newsapi.org/v2/top-headlines?"
-	sourceUrl        = "https://newsapi.org/v2/top-headlines/sources?"
 )
 
 var (
@@ -85,219 +106,195 @@ var (
 	allowedCategories = []string{"business", "entertainment", "general", "health", "science", "sports", "technology"}
 	allowedSearchIn   = []string{"title", "description", "content"}
 	allowedQueryTypes = []string{"everything", "top-headlines", "sources"}
-	queryTypeUrlMap   = map[string]string{
-		"everything":    everyThingUrl,
-		"top-headlines": topHeadlinesUrl,
-		"sources":       sourceUrl,
-	}
 )
 
-func ConstructQueryURL(queryType string, queryParams map[string]interface{}) (string, error) {
-	apiURL := ""
-	for _, allowedValue := range allowedQueryTypes {
-		if strings.EqualFold(allowedValue, queryType) {
-			apiURL = queryTypeUrlMap[allowedValue]
-			break
-		}
-	}
-	if apiURL == "" {
-		return "", errors.New("invalid query type")
-	}
-
-	apiURL, err := constructURL(queryParams, apiURL)
+// ConstructQueryURL is the map based predecessor of BuildQueryURL. The map is
+// converted into the typed query for queryType before it is encoded, so
+// parameters that query type does not have are dropped. In strict mode
+// unknown parameters, values of an unsupported type and extra values of
+// country, category and language are rejected along with invalid values.
+func ConstructQueryURL(queryType string, queryParams map[string]interface{}, opts ...QueryOption) (string, error) {
+	d := &diagnostics{}
+	options := newQueryOptions(opts)
+	query, err := queryFromParams(queryType, queryParams, options.now(), d)
 	if err != nil {
 		return "", err
 	}
-	return apiURL, nil
-}
-
-func (rep *newsAPI) GetNews(apiURL string) (NewsResp, error) {
-	var (
-		apiKey   = rep.apikey
-		newsResp = NewsResp{}
-	)
-	resp, err := getRequest(apiURL, apiKey)
-	if err != nil {
-		return newsResp, err
-	}
-	err = json.Unmarshal(resp, &newsResp)
-	if err != nil {
-		return newsResp, err
-	}
-	if newsResp.Status == "error" {
-		return NewsResp{}, errors.New(newsResp.Message)
-	}
-	return newsResp, nil
-}
-
-func (rep *newsAPI) GetSources(apiURL string) (SourcesResp, error) {
-	var (
-		apiKey     = rep.apikey
-		sourceResp = SourcesResp{}
-	)
-	resp, err := getRequest(apiURL, apiKey)
-	if err != nil {
-		return sourceResp, err
-	}
-	err = json.Unmarshal(resp, &sourceResp)
-	if err != nil {
-		return sourceResp, err
-	}
-	if sourceResp.Status == "error" {
-		return SourcesResp{}, errors.New(sourceResp.Message)
-	}
-	return sourceResp, nil
-}
-
-func urlEncodeString(str string) string {
-	encodedString := url.QueryEscape(str)
-	return encodedString
+	return buildQueryURL(query, d, options)
 }
 
-func constructURL(queryParams map[string]interface{}, apiURL string) (string, error) {
-	if q, ok := queryParams["q"].(string); ok {
-		q := strings.TrimSpace(q)
-		if len(q) > 500 {
-			return "", errors.New("query string length should be lessthan equalto 500")
-		} else if len(q) < 1 {
-			return "", errors.New("query string length should be greaterthan equalto 1")
-		}
-		apiURL = fmt.Sprintf("%s%s%s", apiURL, "q=", urlEncodeString(q))
-	} else {
-		return "", errors.New("query string is required")
-	}
-	if _, ok := queryParams["searchIn"].([]string); ok {
-		searchIn := checkIfValueAllowedInStringArray(queryParams["searchIn"].([]string), allowedSearchIn)
-		if searchIn != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "searchIn=", searchIn)
+func queryFromParams(queryType string, queryParams map[string]interface{}, now time.Time, d *diagnostics) (Query, error) {
+	matchedType := ""
+	for _, allowedValue := range allowedQueryTypes {
+		if strings.EqualFold(allowedValue, queryType) {
+			matchedType = allowedValue
+			break
 		}
 	}
-	if sourcesArr, ok := queryParams["sources"].([]string); ok {
-		if len(sourcesArr) > 0 {
-			sources := checkIfValueAllowedInStringArray(sourcesArr, []string{})
-			if sources != "" {
-				apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "sources=", urlEncodeString(sources))
-			}
-		} else {
-			apiURL = checkForCountryAndCategory(queryParams, apiURL)
-		}
+	if matchedType == "" {
+		return nil, errors.New("invalid query type")
+	}
 
-	} else {
-		apiURL = checkForCountryAndCategory(queryParams, apiURL)
+	known := map[string]bool{}
+	for _, key := range queryParamOrder {
+		known[key] = true
 	}
-	if _, ok := queryParams["domains"].([]string); ok {
-		domains := checkIfValueAllowedInStringArray(queryParams["domains"].([]string), []string{})
-		if domains != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "domains=", urlEncodeString(domains))
+	unknown := []string{}
+	for key := range queryParams {
+		if !known[key] {
+			unknown = append(unknown, key)
 		}
 	}
-	if _, ok := queryParams["excludeDomains"].([]string); ok {
-		excludeDomains := checkIfValueAllowedInStringArray(queryParams["excludeDomains"].([]string), []string{})
-		if excludeDomains != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "excludeDomains=", urlEncodeString(excludeDomains))
-		}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		d.add(key, queryParams[key], "unknown parameter")
 	}
-	if _, ok := queryParams["from"].(string); ok {
-		if _, ok := queryParams["to"].(string); ok {
-			datesQuery, err := compareForValidtoAndFromDate(queryParams["from"].(string), queryParams["to"].(string))
-			if err == nil {
-				apiURL = fmt.Sprintf("%s%s%s", apiURL, "&", datesQuery)
-			} else {
-				log.Print(err.Error())
-				log.Printf("unable to parse dates rolling back to defaults")
-			}
-		} else {
-			to, err := parseDTString(queryParams["from"].(string))
-			if err == nil {
-				apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "from=", to)
-			} else {
-				log.Print(err.Error())
-				log.Printf("unable to parse to date rolling back to defaults")
-			}
-		}
-	} else if _, ok := queryParams["to"].(string); ok {
-		to, err := parseDTString(queryParams["to"].(string))
-		if err == nil {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "to=", to)
-		} else {
-			log.Print(err.Error())
-			log.Printf("unable to parse to date rolling back to defaults")
-		}
+
+	fields := queryFields{
+		Q:              stringParam(queryParams, "q", d),
+		SearchIn:       listParam[SearchIn](queryParams, "searchIn", d),
+		Sources:        listParam[string](queryParams, "sources", d),
+		Domains:        listParam[string](queryParams, "domains", d),
+		ExcludeDomains: listParam[string](queryParams, "excludeDomains", d),
+		Country:        listParam[Country](queryParams, "country", d),
+		Category:       listParam[Category](queryParams, "category", d),
+		From:           timeParam(queryParams, "from", now, d),
+		To:             timeParam(queryParams, "to", now, d),
+		Language:       listParam[Language](queryParams, "language", d),
+		SortBy:         SortBy(stringParam(queryParams, "sortBy", d)),
 	}
-	if _, ok := queryParams["language"].([]string); ok {
-		languages := checkIfValueAllowedInStringArray(queryParams["language"].([]string), allowedLanguage)
-		if languages != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "language=", languages)
+	if pageSize, ok := intParam(queryParams, "pageSize", d); ok {
+		fields.PageSize = pageSize
+		if pageSize == 0 {
+			d.add("pageSize", pageSize, fmt.Sprintf("should be greaterthan equalto 1, using %d", defaultPageSize))
+			fields.PageSize = defaultPageSize
 		}
 	}
-	if _, ok := queryParams["sortBy"].(string); ok {
-		sortBy := defaultSortBy
-		for _, allowedValue := range allowedSortBys {
-			if strings.EqualFold(allowedValue, queryParams["sortBy"].(string)) {
-				sortBy = allowedValue
-				break
-			}
+	if page, ok := intParam(queryParams, "page", d); ok {
+		fields.Page = page
+		if page == 0 {
+			d.add("page", page, fmt.Sprintf("should be greaterthan equalto 1, using %d", defaultPage))
+			fields.Page = defaultPage
 		}
-		apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "sortBy=", sortBy)
 	}
-	if pageSize, ok := queryParams["pageSize"].(int64); ok {
-		if pageSize < 1 {
-			pageSize = defaultPageSize
-		}
-		if pageSize > 100 {
-			log.Printf("page number is greater than maxPage size allowed using maxAllowed Value of 100")
-			pageSize = maxpageSize
-		}
-		apiURL = fmt.Sprintf("%s%s%s%d", apiURL, "&", "pageSize=", pageSize)
+	switch matchedType {
+	case "everything":
+		return queryFor(EndpointEverything, fields, d), nil
+	case "top-headlines":
+		return queryFor(EndpointTopHeadlines, fields, d), nil
+	default:
+		return queryFor(EndpointSources, fields, d), nil
 	}
-	if page, ok := queryParams["page"].(int64); ok {
-		if page < 1 {
-			log.Printf("page number is less than 1 using default value")
-			page = defaultPage
-		}
-		apiURL = fmt.Sprintf("%s%s%s%d", apiURL, "&", "page=", page)
+}
+
+func stringParam(queryParams map[string]interface{}, key string, d *diagnostics) string {
+	switch value := queryParams[key].(type) {
+	case string:
+		return value
+	case SortBy:
+		return string(value)
+	case nil:
+	default:
+		d.add(key, value, fmt.Sprintf("unsupported type %T", value))
 	}
-	return apiURL, nil
+	return ""
 }
 
-func checkForCountryAndCategory(queryParams map[string]interface{}, apiURL string) string {
-	if _, ok := queryParams["country"].([]string); ok {
-		country := checkIfValueAllowedInStringArray(queryParams["country"].([]string), allowedCountries)
-		if country != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "country=", country)
+func listParam[T ~string](queryParams map[string]interface{}, key string, d *diagnostics) []T {
+	list := []T{}
+	switch value := queryParams[key].(type) {
+	case []string:
+		for _, v := range value {
+			list = append(list, T(v))
 		}
+	case []T:
+		list = append(list, value...)
+	case string:
+		list = append(list, T(value))
+	case T:
+		list = append(list, value)
+	case nil:
+	default:
+		d.add(key, value, fmt.Sprintf("unsupported type %T", value))
 	}
-	if _, ok := queryParams["category"].([]string); ok {
-		category := checkIfValueAllowedInStringArray(queryParams["category"].([]string), allowedCategories)
-		if category != "" {
-			apiURL = fmt.Sprintf("%s%s%s%s", apiURL, "&", "category=", category)
-		}
+	return list
+}
+
+func intParam(queryParams map[string]interface{}, key string, d *diagnostics) (int64, bool) {
+	switch value := queryParams[key].(type) {
+	case int:
+		return int64(value), true
+	case int8:
+		return int64(value), true
+	case int16:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case int64:
+		return value, true
+	case uint:
+		return int64(value), true
+	case uint8:
+		return int64(value), true
+	case uint16:
+		return int64(value), true
+	case uint32:
+		return int64(value), true
+	case uint64:
+		return int64(value), true
+	case nil:
+	default:
+		d.add(key, value, fmt.Sprintf("unsupported type %T", value))
 	}
-	return apiURL
+	return 0, false
 }
 
 // ISO 8601
-func parseDTString(dateTimeString string) (string, error) {
-	_, err := time.Parse(time.RFC3339, dateTimeString)
-	if err != nil {
-		return "", err
+func timeParam(queryParams map[string]interface{}, key string, now time.Time, d *diagnostics) time.Time {
+	switch value := queryParams[key].(type) {
+	case time.Time:
+		return value.UTC()
+	case string:
+		parsed, err := parseQueryTime(value, now, key == "to")
+		if err != nil {
+			d.add(key, value, err.Error())
+			return time.Time{}
+		}
+		return parsed
+	case nil:
+	default:
+		d.add(key, value, fmt.Sprintf("unsupported type %T", value))
 	}
-	return dateTimeString, nil
+	return time.Time{}
+}
+
+func (rep *newsAPI) GetNews(apiURL string) (NewsResp, error) {
+	return rep.GetNewsContext(context.Background(), apiURL)
 }
 
-func compareForValidtoAndFromDate(fromDate, toDate string) (string, error) {
-	parsedToDate, err := time.Parse(time.RFC3339, toDate)
+func (rep *newsAPI) GetNewsContext(ctx context.Context, apiURL string) (NewsResp, error) {
+	newsResp, err := getJSON[NewsResp](ctx, rep, apiURL)
 	if err != nil {
-		return "", err
+		return NewsResp{}, err
 	}
-	parsedFromDate, err := time.Parse(time.RFC3339, fromDate)
+	return newsResp, nil
+}
+
+func (rep *newsAPI) GetSources(apiURL string) (SourcesResp, error) {
+	return rep.GetSourcesContext(context.Background(), apiURL)
+}
+
+func (rep *newsAPI) GetSourcesContext(ctx context.Context, apiURL string) (SourcesResp, error) {
+	sourceResp, err := getJSON[SourcesResp](ctx, rep, apiURL)
 	if err != nil {
-		return "", err
-	}
-	if parsedToDate.Before(parsedFromDate) {
-		return "", errors.New("invalid dated: to date timestamp is before from date timestamp")
+		return SourcesResp{}, err
 	}
-	return fmt.Sprintf("%s%s%s%s", "from=", fromDate, "&to=", toDate), nil
+	return sourceResp, nil
+}
+
+func urlEncodeString(str string) string {
+	encodedString := url.QueryEscape(str)
+	return encodedString
 }
 
 func checkIfValueAllowedInStringArray(strArr []string, allowedArray []string) string {
@@ -322,18 +319,141 @@ func checkIfValueAllowedInStringArray(strArr []string, allowedArray []string) st
 	return queryString
 }
 
-func getRequest(url, apiKey string) ([]byte, error) {
+type apiResponse struct {
+	body       []byte
+	statusCode int
+	header     http.Header
+	url        string
+}
+
+// getJSON sends a GET request for apiURL, retrying as allowed by the retry
+// policy, and decodes the final response into a T.
+func getJSON[T any](ctx context.Context, rep *newsAPI, apiURL string) (T, error) {
+	var out T
+	req, err := rep.newRequest(ctx, apiURL)
+	if err != nil {
+		return out, err
+	}
+	logURL := slog.String("url", redactSecrets(RedactURL(req.URL.String()), rep.apikey, req.URL))
+	for attempt := 1; ; attempt++ {
+		out = *new(T)
+		if err := rep.limiter.wait(ctx); err != nil {
+			rep.logger.LogAttrs(ctx, slog.LevelWarn, "newsapi request not sent", logURL,
+				slog.String("error", redactSecrets(err.Error(), rep.apikey, req.URL)))
+			return out, err
+		}
+		rep.logger.LogAttrs(ctx, slog.LevelDebug, "newsapi request", logURL, slog.Int("attempt", attempt))
+		started := time.Now()
+		resp, err := rep.getRequest(req.Clone(ctx))
+		if err == nil {
+			err = decodeResponse(resp, &out)
+		}
+		attrs := []slog.Attr{logURL, slog.Int("attempt", attempt), slog.Duration("duration", time.Since(started))}
+		if resp != nil {
+			attrs = append(attrs, slog.Int("status", resp.statusCode))
+		}
+		if err == nil {
+			rep.logger.LogAttrs(ctx, slog.LevelDebug, "newsapi response", attrs...)
+			return out, nil
+		}
+		attrs = append(attrs, slog.String("error", redactSecrets(err.Error(), rep.apikey, req.URL)))
+		rep.logger.LogAttrs(ctx, slog.LevelWarn, "newsapi request failed", attrs...)
+		delay, retry := rep.retryPolicy.nextDelay(ctx, attempt, resp, err)
+		if !retry {
+			return out, err
+		}
+		rep.logger.LogAttrs(ctx, slog.LevelInfo, "newsapi retry", logURL, slog.Int("attempt", attempt+1), slog.Duration("delay", delay))
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return out, err
+		}
+	}
+}
+
+func (rep *newsAPI) newRequest(ctx context.Context, url string) (*http.Request, error) {
 	method := "GET"
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, rep.resolveURL(url), nil)
 	if err != nil {
-		return []byte{}, err
+		return nil, err
 	}
-	req.Header.Add("X-Api-Key", apiKey)
-	res, err := client.Do(req)
+	req.Header.Add("X-Api-Key", rep.apikey)
+	if rep.userAgent != "" {
+		req.Header.Set("User-Agent", rep.userAgent)
+	}
+	return req, nil
+}
+
+// getRequest sends req once. The returned response is non-nil whenever the
+// server answered, even if the answer is rejected with a ResponseError.
+func (rep *newsAPI) getRequest(req *http.Request) (*apiResponse, error) {
+	res, err := rep.httpClient.Do(req)
 	if err != nil {
-		return []byte{}, err
+		return nil, err
 	}
 	defer res.Body.Close()
-	return io.ReadAll(res.Body)
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiResponse{
+		body:       body,
+		statusCode: res.StatusCode,
+		header:     res.Header,
+		url:        req.URL.String(),
+	}
+	if len(body) > maxResponseBytes {
+		resp.body = body[:maxResponseBytes]
+		return resp, newResponseError(resp, "response body too large")
+	}
+	if !isJSONResponse(resp) {
+		if len(body) == 0 {
+			return resp, newResponseError(resp, "empty response body")
+		}
+		return resp, newResponseError(resp, "response is not json")
+	}
+	return resp, nil
+}
+
+type responseEnvelope struct {
+	Status  string `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func decodeResponse(resp *apiResponse, out interface{}) error {
+	err := json.Unmarshal(resp.body, out)
+	if err != nil {
+		return newResponseError(resp, "invalid json: "+err.Error())
+	}
+	envelope := responseEnvelope{}
+	if err := json.Unmarshal(resp.body, &envelope); err == nil && envelope.Status == "error" {
+		return newAPIError(envelope.Code, envelope.Message, resp)
+	}
+	if resp.statusCode < 200 || resp.statusCode > 299 {
+		return newResponseError(resp, "unexpected status code")
+	}
+	return nil
+}
+
+// isJSONResponse accepts JSON content types and, when the content type is
+// missing or text/plain, bodies that look like a JSON object.
+func isJSONResponse(resp *apiResponse) bool {
+	body := bytes.TrimSpace(resp.body)
+	if len(body) == 0 {
+		return false
+	}
+	mediaType := ""
+	if contentType := resp.header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return false
+		}
+		mediaType = parsed
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return true
+	case mediaType == "" || mediaType == "text/plain":
+		return bytes.HasPrefix(body, []byte("{"))
+	}
+	return false
 }
@@ -1,22 +1,22 @@
 package news_api_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	news_api "github.com/aekam27/newsAPIWrapper"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
-type NewsAPIDAO struct {
-	mock.Mock
-}
-
 func TestNewsAPI(t *testing.T) {
 
 	t.Run("Construct url with invalid query type", func(t *testing.T) {
@@ -32,19 +32,23 @@ func TestNewsAPI(t *testing.T) {
 		}
 	})
 	t.Run("Construct url with no search string", func(t *testing.T) {
-		queryTypes := []string{"everything", "top-headlines", "sources"}
-		for _, i := range queryTypes {
-			_, err := news_api.ConstructQueryURL(i, map[string]interface{}{})
-			assert.EqualError(t, err, "query string is required")
-		}
+		_, err := news_api.ConstructQueryURL("everything", map[string]interface{}{})
+		assert.EqualError(t, err, "one of q, sources or domains is required")
+		_, err = news_api.ConstructQueryURL("top-headlines", map[string]interface{}{})
+		assert.EqualError(t, err, "one of q, sources, country or category is required")
+		qurl, err := news_api.ConstructQueryURL("sources", map[string]interface{}{})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines/sources?", qurl)
 	})
 
 	t.Run("Construct url with no search string", func(t *testing.T) {
-		queryTypes := []string{"everything", "top-headlines", "sources"}
+		queryTypes := []string{"everything", "top-headlines"}
 		for _, i := range queryTypes {
 			_, err := news_api.ConstructQueryURL(i, map[string]interface{}{"q": "    "})
 			assert.EqualError(t, err, "query string length should be greaterthan equalto 1")
 		}
+		_, err := news_api.ConstructQueryURL("sources", map[string]interface{}{"q": "    "})
+		assert.Nil(t, err)
 	})
 
 	t.Run("Construct url with search string length greater than 500 characters", func(t *testing.T) {
@@ -56,11 +60,14 @@ func TestNewsAPI(t *testing.T) {
 		queryParams := map[string]interface{}{
 			"q": string(randStr),
 		}
-		queryTypes := []string{"everything", "top-headlines", "sources"}
+		queryTypes := []string{"everything", "top-headlines"}
 		for _, i := range queryTypes {
 			_, err := news_api.ConstructQueryURL(i, queryParams)
 			assert.EqualError(t, err, "query string length should be lessthan equalto 500")
 		}
+		qurl, err := news_api.ConstructQueryURL("sources", queryParams)
+		assert.Nil(t, err)
+		assert.Equal(t, false, strings.Contains(qurl, "q="))
 	})
 
 	t.Run("Construct url with valid search string and searchIn value", func(t *testing.T) {
@@ -69,7 +76,7 @@ func TestNewsAPI(t *testing.T) {
 			qurl, err := news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "searchIn": []string{"title", "content"}})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, "searchIn=title,content"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "searchIn=title,content"))
 		}
 	})
 
@@ -79,13 +86,13 @@ func TestNewsAPI(t *testing.T) {
 			qurl, err := news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "searchIn": []string{"title", "contenting"}})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, "searchIn=title"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "searchIn=title"))
 			assert.Equal(t, false, strings.Contains(qurl, "contenting"))
 		}
 	})
 
 	t.Run("Construct url with valid search string, sources, country, category value", func(t *testing.T) {
-		queryTypes := []string{"everything", "top-headlines", "sources"}
+		queryTypes := []string{"everything", "top-headlines"}
 		for _, i := range queryTypes {
 			qurl, err := news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "sources": []string{"a", "b"},
 				"country": []string{"us", "uk"}, "category": []string{"cat1", "cat2"}})
@@ -95,6 +102,11 @@ func TestNewsAPI(t *testing.T) {
 			assert.Equal(t, false, strings.Contains(qurl, "country="))
 			assert.Equal(t, false, strings.Contains(qurl, "category="))
 		}
+
+		qurl, err := news_api.ConstructQueryURL("sources", map[string]interface{}{"q": "apple", "sources": []string{"a", "b"},
+			"country": []string{"us", "uk"}, "category": []string{"cat1", "cat2"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines/sources?country=us", qurl)
 	})
 
 	t.Run("Construct url with valid search string, empty sources, country, category value", func(t *testing.T) {
@@ -105,8 +117,8 @@ func TestNewsAPI(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
 			assert.Equal(t, false, strings.Contains(qurl, "sources="))
-			assert.Equal(t, true, strings.Contains(qurl, "country=us"))
-			assert.Equal(t, true, strings.Contains(qurl, "category=business"))
+			assert.Equal(t, i != "everything", strings.Contains(qurl, "country=us"))
+			assert.Equal(t, i != "everything", strings.Contains(qurl, "category=business"))
 		}
 	})
 
@@ -117,9 +129,9 @@ func TestNewsAPI(t *testing.T) {
 				"excludeDomains": []string{"twitter.com", "x.com"}, "language": []string{"en", "ep"}})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, fmt.Sprintf("%s%s", "domains=", url.QueryEscape("abc.com,xyz.com"))))
-			assert.Equal(t, true, strings.Contains(qurl, fmt.Sprintf("%s%s", "excludeDomains=", url.QueryEscape("twitter.com,x.com"))))
-			assert.Equal(t, true, strings.Contains(qurl, "language=en"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, fmt.Sprintf("%s%s", "domains=", url.QueryEscape("abc.com,xyz.com"))))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, fmt.Sprintf("%s%s", "excludeDomains=", url.QueryEscape("twitter.com,x.com"))))
+			assert.Equal(t, i != "top-headlines", strings.Contains(qurl, "language=en"))
 		}
 	})
 
@@ -131,15 +143,15 @@ func TestNewsAPI(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
 			assert.Equal(t, false, strings.Contains(qurl, "sortBy="))
-			assert.Equal(t, true, strings.Contains(qurl, "pageSize=100"))
-			assert.Equal(t, true, strings.Contains(qurl, "page=1"))
+			assert.Equal(t, i != "sources", strings.Contains(qurl, "pageSize=100"))
+			assert.Equal(t, i != "sources", strings.Contains(qurl, "page=1"))
 
 			qurl, err = news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "sortBy": "publishedAt",
 				"pageSize": int64(101)})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, "sortBy=publishedAt"))
-			assert.Equal(t, true, strings.Contains(qurl, "pageSize=100"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "sortBy=publishedAt"))
+			assert.Equal(t, i != "sources", strings.Contains(qurl, "pageSize=100"))
 		}
 	})
 
@@ -150,8 +162,8 @@ func TestNewsAPI(t *testing.T) {
 				"to": "2024-01-05T15:04:05Z"})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, "from=2024-01-02T00:00:00Z"))
-			assert.Equal(t, true, strings.Contains(qurl, "to=2024-01-05T15:04:05Z"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "from=2024-01-02T00:00:00Z"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "to=2024-01-05T15:04:05Z"))
 		}
 	})
 
@@ -174,11 +186,11 @@ func TestNewsAPI(t *testing.T) {
 				"to": "2024-01-05T15:04:05Z"})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, true, strings.Contains(qurl, "to=2024-01-05T15:04:05Z"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "to=2024-01-05T15:04:05Z"))
 
 			qurl, err = news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "from": "2024-01-05T15:04:05Z"})
 			assert.Nil(t, err)
-			assert.Equal(t, true, strings.Contains(qurl, "from=2024-01-05T15:04:05Z"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "from=2024-01-05T15:04:05Z"))
 		}
 	})
 
@@ -189,11 +201,15 @@ func TestNewsAPI(t *testing.T) {
 				"to": "2024-01-05T15:04:05"})
 			assert.Nil(t, err)
 			assert.Equal(t, true, strings.Contains(qurl, i))
-			assert.Equal(t, false, strings.Contains(qurl, "to=2024-01-05T15:04:05"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "to=2024-01-05T15:04:05Z"))
 
 			qurl, err = news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "from": "2024-01-05"})
 			assert.Nil(t, err)
-			assert.Equal(t, false, strings.Contains(qurl, "from=2024-01-05"))
+			assert.Equal(t, i == "everything", strings.Contains(qurl, "from=2024-01-05T00:00:00Z"))
+
+			qurl, err = news_api.ConstructQueryURL(i, map[string]interface{}{"q": "apple", "from": "last tuesday"})
+			assert.Nil(t, err)
+			assert.Equal(t, false, strings.Contains(qurl, "from="))
 		}
 	})
 
@@ -231,3 +247,37 @@ func TestNewsAPI(t *testing.T) {
 		}
 	})
 }
+
+func TestNewsAPIContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx")
+	assert.Nil(t, err)
+
+	t.Run("Deadline reaches the http request", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := newsAPI.GetNewsContext(ctx, server.URL+"/v2/everything?q=apple")
+		assert.NotNil(t, err)
+		assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("Cancellation reaches the http request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		_, err := newsAPI.GetSourcesContext(ctx, server.URL+"/v2/top-headlines/sources")
+		assert.NotNil(t, err)
+		assert.Equal(t, true, errors.Is(err, context.Canceled))
+	})
+}
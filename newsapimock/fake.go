@@ -0,0 +1,85 @@
+package newsapimock
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapitest"
+)
+
+// Fake is an in-memory news_api.NewsAPIDAO. It parses the URLs built by
+// ConstructQueryURL or BuildQueryURL and answers them from Articles and
+// Sources with the same filtering rules as newsapitest.Server, but without
+// plan limits or any HTTP.
+type Fake struct {
+	Articles []news_api.Articles
+	Sources  []news_api.Sources
+}
+
+var _ news_api.NewsAPIDAO = (*Fake)(nil)
+
+// NewFake returns a Fake answering from articles and sources.
+func NewFake(articles []news_api.Articles, sources []news_api.Sources) *Fake {
+	return &Fake{Articles: articles, Sources: sources}
+}
+
+func (f *Fake) GetNews(apiURL string) (news_api.NewsResp, error) {
+	return f.GetNewsContext(context.Background(), apiURL)
+}
+
+func (f *Fake) GetSources(apiURL string) (news_api.SourcesResp, error) {
+	return f.GetSourcesContext(context.Background(), apiURL)
+}
+
+func (f *Fake) GetNewsContext(ctx context.Context, apiURL string) (news_api.NewsResp, error) {
+	if err := ctx.Err(); err != nil {
+		return news_api.NewsResp{}, err
+	}
+	endpoint, params, err := news_api.SplitQueryURL(apiURL)
+	if err != nil {
+		return news_api.NewsResp{}, err
+	}
+	corpus := newsapitest.Corpus{Articles: f.Articles, Sources: f.Sources}
+	var (
+		resp   news_api.NewsResp
+		apiErr *news_api.APIError
+	)
+	switch endpoint {
+	case news_api.EndpointEverything:
+		resp, apiErr = corpus.SearchEverything(params, newsapitest.Plan{}, time.Now())
+	case news_api.EndpointTopHeadlines:
+		resp, apiErr = corpus.SearchTopHeadlines(params, newsapitest.Plan{})
+	default:
+		apiErr = routeNotFound(apiURL)
+	}
+	if apiErr != nil {
+		apiErr.URL = news_api.RedactURL(apiURL)
+		return news_api.NewsResp{}, apiErr
+	}
+	return resp, nil
+}
+
+func (f *Fake) GetSourcesContext(ctx context.Context, apiURL string) (news_api.SourcesResp, error) {
+	if err := ctx.Err(); err != nil {
+		return news_api.SourcesResp{}, err
+	}
+	endpoint, params, err := news_api.SplitQueryURL(apiURL)
+	if err != nil {
+		return news_api.SourcesResp{}, err
+	}
+	if endpoint != news_api.EndpointSources {
+		return news_api.SourcesResp{}, routeNotFound(apiURL)
+	}
+	resp, apiErr := newsapitest.Corpus{Articles: f.Articles, Sources: f.Sources}.SearchSources(params)
+	if apiErr != nil {
+		apiErr.URL = news_api.RedactURL(apiURL)
+		return news_api.SourcesResp{}, apiErr
+	}
+	return resp, nil
+}
+
+func routeNotFound(apiURL string) *news_api.APIError {
+	return &news_api.APIError{Code: "routeNotFound", Message: "The requested route does not exist.", HTTPStatus: http.StatusNotFound, URL: news_api.RedactURL(apiURL)}
+}
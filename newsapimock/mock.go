@@ -0,0 +1,53 @@
+// Package newsapimock provides test doubles for news_api.NewsAPIDAO: a
+// testify mock for asserting calls and an in-memory Fake for exercising code
+// against a handful of articles and sources.
+package newsapimock
+
+import (
+	"context"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// NewsAPIDAO is a testify mock of news_api.NewsAPIDAO. Return values set with
+// On(...).Return may be the response and error, or a function with the same
+// signature as the mocked method.
+type NewsAPIDAO struct {
+	mock.Mock
+}
+
+var _ news_api.NewsAPIDAO = (*NewsAPIDAO)(nil)
+
+func (m *NewsAPIDAO) GetNews(apiURL string) (news_api.NewsResp, error) {
+	args := m.Called(apiURL)
+	if fn, ok := args.Get(0).(func(string) (news_api.NewsResp, error)); ok {
+		return fn(apiURL)
+	}
+	return args.Get(0).(news_api.NewsResp), args.Error(1)
+}
+
+func (m *NewsAPIDAO) GetSources(apiURL string) (news_api.SourcesResp, error) {
+	args := m.Called(apiURL)
+	if fn, ok := args.Get(0).(func(string) (news_api.SourcesResp, error)); ok {
+		return fn(apiURL)
+	}
+	return args.Get(0).(news_api.SourcesResp), args.Error(1)
+}
+
+func (m *NewsAPIDAO) GetNewsContext(ctx context.Context, apiURL string) (news_api.NewsResp, error) {
+	args := m.Called(ctx, apiURL)
+	if fn, ok := args.Get(0).(func(context.Context, string) (news_api.NewsResp, error)); ok {
+		return fn(ctx, apiURL)
+	}
+	return args.Get(0).(news_api.NewsResp), args.Error(1)
+}
+
+func (m *NewsAPIDAO) GetSourcesContext(ctx context.Context, apiURL string) (news_api.SourcesResp, error) {
+	args := m.Called(ctx, apiURL)
+	if fn, ok := args.Get(0).(func(context.Context, string) (news_api.SourcesResp, error)); ok {
+		return fn(ctx, apiURL)
+	}
+	return args.Get(0).(news_api.SourcesResp), args.Error(1)
+}
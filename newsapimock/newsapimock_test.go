@@ -0,0 +1,115 @@
+package newsapimock_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapimock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// headlines is the kind of consumer code the doubles stand in for.
+func headlines(dao news_api.NewsAPIDAO, q string) ([]string, error) {
+	qurl, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": q, "sortBy": "publishedAt"})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dao.GetNews(qurl)
+	if err != nil {
+		return nil, err
+	}
+	titles := []string{}
+	for _, article := range resp.Articles {
+		titles = append(titles, article.Title)
+	}
+	return titles, nil
+}
+
+func TestNewsAPIDAO(t *testing.T) {
+	t.Run("Returns configured values", func(t *testing.T) {
+		dao := &newsapimock.NewsAPIDAO{}
+		dao.On("GetNews", mock.Anything).Return(news_api.NewsResp{Status: "ok", Articles: []news_api.Articles{{Title: "Apple"}}}, nil).Once()
+		dao.On("GetNews", mock.Anything).Return(news_api.NewsResp{}, news_api.ErrRateLimited)
+		titles, err := headlines(dao, "apple")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple"}, titles)
+		_, err = headlines(dao, "apple")
+		assert.Equal(t, true, news_api.IsRateLimited(err))
+		dao.AssertNumberOfCalls(t, "GetNews", 2)
+	})
+
+	t.Run("Returns values from functions", func(t *testing.T) {
+		dao := &newsapimock.NewsAPIDAO{}
+		dao.On("GetSourcesContext", mock.Anything, "https://newsapi.org/v2/top-headlines/sources?country=us").
+			Return(func(ctx context.Context, apiURL string) (news_api.SourcesResp, error) {
+				return news_api.SourcesResp{Status: "ok"}, ctx.Err()
+			})
+		ctx, cancel := context.WithCancel(context.Background())
+		resp, err := dao.GetSourcesContext(ctx, "https://newsapi.org/v2/top-headlines/sources?country=us")
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", resp.Status)
+		cancel()
+		_, err = dao.GetSourcesContext(ctx, "https://newsapi.org/v2/top-headlines/sources?country=us")
+		assert.Equal(t, true, errors.Is(err, context.Canceled))
+		dao.AssertExpectations(t)
+	})
+}
+
+func TestFake(t *testing.T) {
+	sourceID := "techcrunch"
+	fake := newsapimock.NewFake([]news_api.Articles{
+		{Source: news_api.NewArticleSource(sourceID, "TechCrunch"), Title: "Apple ships a new iPhone", Url: "https://techcrunch.com/1", PublishedAt: "2024-01-02T10:00:00Z"},
+		{Source: news_api.NewArticleSource("", "Blog"), Title: "Why I left Google", Url: "https://blog.example.com/2", PublishedAt: "2024-01-03T10:00:00Z"},
+		{Source: news_api.NewArticleSource(sourceID, "TechCrunch"), Title: "Apple and Google settle", Url: "https://techcrunch.com/3", PublishedAt: "2024-01-04T10:00:00Z"},
+	}, []news_api.Sources{
+		{Id: sourceID, Name: "TechCrunch", Category: "technology", Language: "en", Country: "us"},
+	})
+
+	t.Run("Everything", func(t *testing.T) {
+		titles, err := headlines(fake, "apple")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple and Google settle", "Apple ships a new iPhone"}, titles)
+		titles, err = headlines(fake, "google -apple")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Why I left Google"}, titles)
+	})
+
+	t.Run("Top headlines and sources", func(t *testing.T) {
+		qurl, err := news_api.BuildQueryURL(news_api.TopHeadlinesQuery{Q: "google", Sources: []string{sourceID}})
+		assert.Nil(t, err)
+		resp, err := fake.GetNews(qurl)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, resp.TotalResults)
+
+		qurl, err = news_api.ConstructQueryURL("sources", map[string]interface{}{"q": "tech", "country": []string{"us"}})
+		assert.Nil(t, err)
+		sources, err := fake.GetSources(qurl)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(sources.Sources))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := fake.GetNews("https://newsapi.org/v2/everything?sources=unknown")
+		assert.ErrorIs(t, err, news_api.ErrSourceDoesNotExist)
+		_, err = fake.GetSources("https://newsapi.org/v2/everything?q=apple")
+		var apiErr *news_api.APIError
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, "routeNotFound", apiErr.Code)
+
+		_, err = fake.GetNews("https://newsapi.org/v2/everything?sources=unknown&apiKey=secret")
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, "https://newsapi.org/v2/everything?apiKey=REDACTED&sources=unknown", apiErr.URL)
+		_, err = fake.GetSources("https://newsapi.org/v2/everything?apiKey=secret")
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, false, strings.Contains(apiErr.URL, "secret"))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = fake.GetNewsContext(ctx, "https://newsapi.org/v2/everything?q=apple")
+		assert.Equal(t, true, errors.Is(err, context.Canceled))
+	})
+}
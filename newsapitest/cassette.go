@@ -0,0 +1,292 @@
+package newsapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// ErrNoInteraction is returned by a Recorder in replay mode when the cassette
+// has no interaction matching a request.
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay answers every request from the cassette and never touches
+	// the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request and records a fresh cassette.
+	ModeRecord
+	// ModeUpdate replays recorded interactions and records the missing ones.
+	// Under MatchStrict they are inserted in call order.
+	ModeUpdate
+)
+
+// Matching selects how a Recorder finds the interaction for a request.
+type Matching int
+
+const (
+	// MatchStrict replays interactions in recorded order, each once, and
+	// requires the method and URL to be identical.
+	MatchStrict Matching = iota
+	// MatchLenient replays any interaction with the same method, path and
+	// query parameters, in any order and as often as needed.
+	MatchLenient
+)
+
+// Cassette is the on-disk form of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response. API keys are
+// replaced with REDACTED before they are stored.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request interactions are matched on.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// RecordedResponse is replayed as the response to a matching request.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records and replays NewsAPI
+// interactions to a JSON cassette file. Pass it to news_api.WithTransport
+// and call Save once the test is done.
+type Recorder struct {
+	path      string
+	mode      Mode
+	matching  Matching
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	next     int
+	changed  bool
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithMatching sets how requests are matched to recorded interactions. The
+// default is MatchStrict.
+func WithMatching(matching Matching) RecorderOption {
+	return func(r *Recorder) {
+		r.matching = matching
+	}
+}
+
+// WithRecorderTransport sets the transport requests are recorded from. The
+// default is http.DefaultTransport.
+func WithRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// NewRecorder returns a Recorder for the cassette at path. ModeReplay needs
+// the file to exist; ModeUpdate starts from it when it does and ModeRecord
+// ignores it.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if mode != ModeRecord {
+		cassette, err := LoadCassette(path)
+		if err != nil && (mode == ModeReplay || !errors.Is(err, os.ErrNotExist)) {
+			return nil, err
+		}
+		r.cassette = cassette
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// LoadCassette reads the cassette at path.
+func LoadCassette(path string) (Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Cassette{}, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return Cassette{}, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the cassette to its file when anything new was recorded.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.changed && r.mode != ModeRecord {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	r.changed = false
+	return nil
+}
+
+// RoundTrip replays or records req depending on the Recorder's mode.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := recordRequest(req)
+	if r.mode != ModeRecord {
+		r.mu.Lock()
+		interaction, ok := r.match(recorded)
+		r.mu.Unlock()
+		if ok {
+			return interaction.Response.response(req), nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+		}
+	}
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	interaction := Interaction{
+		Request:  recorded,
+		Response: RecordedResponse{StatusCode: res.StatusCode, Header: res.Header.Clone(), Body: string(body)},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode == ModeUpdate && r.matching == MatchStrict {
+		// Keep the cassette in call order so strict replay finds the new
+		// interaction where it was made.
+		r.cassette.Interactions = slices.Insert(r.cassette.Interactions, r.next, interaction)
+		r.used = slices.Insert(r.used, r.next, true)
+		r.next++
+	} else {
+		r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+		r.used = append(r.used, true)
+	}
+	r.changed = true
+	return res, nil
+}
+
+// match finds the interaction for req and marks it used. Callers hold r.mu.
+func (r *Recorder) match(req RecordedRequest) (Interaction, bool) {
+	if r.matching == MatchStrict {
+		if r.next >= len(r.cassette.Interactions) {
+			return Interaction{}, false
+		}
+		interaction := r.cassette.Interactions[r.next]
+		if interaction.Request.Method != req.Method || interaction.Request.URL != req.URL {
+			return Interaction{}, false
+		}
+		r.used[r.next] = true
+		r.next++
+		return interaction, true
+	}
+	found := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Request.Method == req.Method && sameQuery(interaction.Request.URL, req.URL) {
+			found = i
+			if !r.used[i] {
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return Interaction{}, false
+	}
+	r.used[found] = true
+	return r.cassette.Interactions[found], true
+}
+
+// sameQuery reports whether two URLs have the same path and query parameters,
+// ignoring the host and the order of the parameters.
+func sameQuery(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	if strings.TrimSuffix(ua.Path, "/") != strings.TrimSuffix(ub.Path, "/") {
+		return false
+	}
+	qa, qb := ua.Query(), ub.Query()
+	if len(qa) != len(qb) {
+		return false
+	}
+	for key, values := range qa {
+		if strings.Join(values, ",") != strings.Join(qb[key], ",") {
+			return false
+		}
+	}
+	return true
+}
+
+func recordRequest(req *http.Request) RecordedRequest {
+	header := req.Header.Clone()
+	for key := range header {
+		if strings.EqualFold(key, "X-Api-Key") || strings.EqualFold(key, "Authorization") {
+			header[key] = []string{redacted}
+		}
+	}
+	return RecordedRequest{Method: req.Method, URL: news_api.RedactURL(req.URL.String()), Header: header}
+}
+
+const redacted = "REDACTED"
+
+func (r RecordedResponse) response(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
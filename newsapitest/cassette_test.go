@@ -0,0 +1,125 @@
+package newsapitest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "newsapi.json")
+	apple := "https://newsapi.org/v2/everything?q=apple&sortBy=publishedAt"
+	sources := "https://newsapi.org/v2/top-headlines/sources?country=us"
+
+	client := func(t *testing.T, recorder *newsapitest.Recorder) news_api.NewsAPIDAO {
+		newsAPI, err := news_api.InitializeNewsAPI(server.APIKey, news_api.WithBaseURL(server.URL), news_api.WithTransport(recorder))
+		assert.Nil(t, err)
+		return newsAPI
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		recorder, err := newsapitest.NewRecorder(path, newsapitest.ModeRecord)
+		assert.Nil(t, err)
+		newsAPI := client(t, recorder)
+		resp, err := newsAPI.GetNews(apple)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+		_, err = newsAPI.GetSources(sources)
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews(server.URL + "/v2/top-headlines?country=us&apiKey=" + server.APIKey)
+		assert.Nil(t, err)
+		assert.Nil(t, recorder.Save())
+
+		data, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, false, strings.Contains(string(data), server.APIKey))
+		assert.Equal(t, true, strings.Contains(string(data), "REDACTED"))
+		assert.Equal(t, 3, len(recorder.Cassette().Interactions))
+		assert.Equal(t, 3, server.Requests())
+	})
+
+	t.Run("Strict replay", func(t *testing.T) {
+		recorder, err := newsapitest.NewRecorder(path, newsapitest.ModeReplay)
+		assert.Nil(t, err)
+		newsAPI := client(t, recorder)
+		resp, err := newsAPI.GetNews(apple)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+		_, err = newsAPI.GetNews(apple)
+		assert.ErrorIs(t, err, newsapitest.ErrNoInteraction)
+		assert.Equal(t, 3, server.Requests())
+	})
+
+	t.Run("Lenient replay", func(t *testing.T) {
+		recorder, err := newsapitest.NewRecorder(path, newsapitest.ModeReplay, newsapitest.WithMatching(newsapitest.MatchLenient))
+		assert.Nil(t, err)
+		newsAPI := client(t, recorder)
+		resp, err := newsAPI.GetSources(sources)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(resp.Sources))
+		for i := 0; i < 2; i++ {
+			resp, err := newsAPI.GetNews("https://newsapi.org/v2/everything?sortBy=publishedAt&q=apple")
+			assert.Nil(t, err)
+			assert.Equal(t, 3, resp.TotalResults)
+		}
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=google")
+		assert.Equal(t, true, errors.Is(err, newsapitest.ErrNoInteraction))
+		assert.Equal(t, 3, server.Requests())
+	})
+
+	t.Run("Update records missing interactions", func(t *testing.T) {
+		recorder, err := newsapitest.NewRecorder(path, newsapitest.ModeUpdate, newsapitest.WithMatching(newsapitest.MatchLenient))
+		assert.Nil(t, err)
+		newsAPI := client(t, recorder)
+		_, err = newsAPI.GetNews(apple)
+		assert.Nil(t, err)
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/everything?q=google")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.TotalResults)
+		assert.Equal(t, 4, server.Requests())
+		assert.Nil(t, recorder.Save())
+
+		cassette, err := newsapitest.LoadCassette(path)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(cassette.Interactions))
+	})
+
+	t.Run("Strict update keeps the call order", func(t *testing.T) {
+		ordered := filepath.Join(t.TempDir(), "ordered.json")
+		google := "https://newsapi.org/v2/everything?q=google"
+		bitcoin := "https://newsapi.org/v2/everything?q=bitcoin"
+		run := func(t *testing.T, mode newsapitest.Mode, urls ...string) {
+			recorder, err := newsapitest.NewRecorder(ordered, mode)
+			assert.Nil(t, err)
+			newsAPI := client(t, recorder)
+			for _, qurl := range urls {
+				_, err := newsAPI.GetNews(qurl)
+				assert.Nil(t, err, qurl)
+			}
+			assert.Nil(t, recorder.Save())
+		}
+		run(t, newsapitest.ModeRecord, apple, google)
+		requests := server.Requests()
+		run(t, newsapitest.ModeUpdate, apple, bitcoin, google)
+		assert.Equal(t, requests+1, server.Requests())
+		run(t, newsapitest.ModeReplay, apple, bitcoin, google)
+		assert.Equal(t, requests+1, server.Requests())
+	})
+
+	t.Run("Replay needs a cassette", func(t *testing.T) {
+		_, err := newsapitest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), newsapitest.ModeReplay)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		recorder, err := newsapitest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), newsapitest.ModeUpdate)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(recorder.Cassette().Interactions))
+	})
+}
@@ -0,0 +1,188 @@
+package newsapitest
+
+import (
+	"bytes"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// FaultKind is the kind of failure a Fault injects.
+type FaultKind string
+
+const (
+	// FaultServerError answers with a NewsAPI unexpectedError payload and a
+	// 500 status, or Fault.Status when set.
+	FaultServerError FaultKind = "serverError"
+	// FaultRateLimited answers with a 429 rateLimited payload and, when
+	// Fault.RetryAfter is set, a Retry-After header.
+	FaultRateLimited FaultKind = "rateLimited"
+	// FaultTruncatedBody serves the normal response cut off halfway through.
+	FaultTruncatedBody FaultKind = "truncatedBody"
+	// FaultHTMLError answers with a proxy style HTML page and a 502 status,
+	// or Fault.Status when set.
+	FaultHTMLError FaultKind = "htmlError"
+	// FaultConnectionReset closes the connection without writing a response.
+	FaultConnectionReset FaultKind = "connectionReset"
+)
+
+// Fault describes what the server does instead of, or before, answering a
+// request. A Fault with only Latency set delays the normal response.
+type Fault struct {
+	Kind       FaultKind
+	Latency    time.Duration
+	Status     int
+	RetryAfter time.Duration
+}
+
+// Rule injects Fault into the requests it selects. Requests are counted per
+// endpoint when Endpoint is set, and across all endpoints otherwise; the rule
+// skips the first After of them and then applies to the next Times, or to
+// all the rest when Times is 0. A non-zero Probability further limits it to
+// that fraction of the selected requests.
+type Rule struct {
+	// Endpoint is one of news_api.EndpointEverything, EndpointTopHeadlines or
+	// EndpointSources. Empty matches every endpoint.
+	Endpoint    string
+	After       int
+	Times       int
+	Probability float64
+	Fault       Fault
+}
+
+// Chaos is the fault injection configuration of a Server. The first Rule
+// that selects a request decides its fault. Seed makes Probability rules
+// deterministic.
+type Chaos struct {
+	Seed  int64
+	Rules []Rule
+}
+
+// WithChaos starts the server with chaos enabled.
+func WithChaos(chaos Chaos) Option {
+	return func(s *Server) {
+		s.setChaos(chaos)
+	}
+}
+
+// SetChaos replaces the fault injection configuration and restarts its
+// request counts. A zero Chaos turns fault injection off.
+func (s *Server) SetChaos(chaos Chaos) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setChaos(chaos)
+}
+
+func (s *Server) setChaos(chaos Chaos) {
+	s.chaos = chaos
+	s.chaosRand = rand.New(rand.NewSource(chaos.Seed))
+	s.chaosCalls = map[string]int{}
+}
+
+// fault counts the request against endpoint and returns the fault to inject,
+// if any.
+func (s *Server) fault(endpoint string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.chaos.Rules) == 0 {
+		return Fault{}, false
+	}
+	s.chaosCalls[""]++
+	s.chaosCalls[endpoint]++
+	for _, rule := range s.chaos.Rules {
+		if rule.Endpoint != "" && rule.Endpoint != endpoint {
+			continue
+		}
+		call := s.chaosCalls[rule.Endpoint]
+		if call <= rule.After || (rule.Times > 0 && call > rule.After+rule.Times) {
+			continue
+		}
+		if rule.Probability > 0 && s.chaosRand.Float64() >= rule.Probability {
+			continue
+		}
+		return rule.Fault, true
+	}
+	return Fault{}, false
+}
+
+// withChaos wraps the handler of endpoint with fault injection.
+func (s *Server) withChaos(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fault, ok := s.fault(endpoint)
+		if !ok {
+			next(w, r)
+			return
+		}
+		if fault.Latency > 0 {
+			timer := time.NewTimer(fault.Latency)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		switch fault.Kind {
+		case FaultServerError:
+			writeError(w, statusOr(fault.Status, http.StatusInternalServerError), news_api.CodeUnexpectedError,
+				"The server encountered an unexpected error. Please try again later.")
+		case FaultRateLimited:
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+			}
+			writeError(w, http.StatusTooManyRequests, news_api.CodeRateLimited,
+				"You have made too many requests recently. Developer accounts are limited to 100 requests over a 24 hour period (50 requests available every 12 hours). Please upgrade to a paid plan if you need more requests.")
+		case FaultTruncatedBody:
+			recorder := httptest.NewRecorder()
+			next(recorder, r)
+			for key, values := range recorder.Header() {
+				w.Header()[key] = values
+			}
+			body := recorder.Body.Bytes()
+			w.WriteHeader(recorder.Code)
+			w.Write(body[:len(body)/2])
+		case FaultHTMLError:
+			status := statusOr(fault.Status, http.StatusBadGateway)
+			text := strconv.Itoa(status) + " " + http.StatusText(status)
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(status)
+			var page bytes.Buffer
+			page.WriteString("<html>\r\n<head><title>" + text + "</title></head>\r\n")
+			page.WriteString("<body>\r\n<center><h1>" + text + "</h1></center>\r\n<hr><center>nginx</center>\r\n</body>\r\n</html>\r\n")
+			w.Write(page.Bytes())
+		case FaultConnectionReset:
+			resetConnection(w)
+		default:
+			next(w, r)
+		}
+	}
+}
+
+// resetConnection drops the connection under w with an RST rather than a
+// clean close, the way a failing load balancer would.
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
+
+func statusOr(status, fallback int) int {
+	if status == 0 {
+		return fallback
+	}
+	return status
+}
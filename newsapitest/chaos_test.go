@@ -0,0 +1,137 @@
+package newsapitest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	newsAPI, err := server.Client()
+	assert.Nil(t, err)
+	everything := "https://newsapi.org/v2/everything?q=apple"
+
+	t.Run("Server errors for a number of requests", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Endpoint: news_api.EndpointEverything, Times: 2, Fault: newsapitest.Fault{Kind: newsapitest.FaultServerError, Status: http.StatusServiceUnavailable}},
+		}})
+		for i := 0; i < 2; i++ {
+			_, err := newsAPI.GetNews(everything)
+			var apiErr *news_api.APIError
+			assert.Equal(t, true, errors.As(err, &apiErr))
+			assert.Equal(t, news_api.CodeUnexpectedError, apiErr.Code)
+			assert.Equal(t, http.StatusServiceUnavailable, apiErr.HTTPStatus)
+		}
+		_, err := newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews(everything)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Rate limited after N calls", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{After: 3, Fault: newsapitest.Fault{Kind: newsapitest.FaultRateLimited, RetryAfter: time.Minute}},
+		}})
+		for i := 0; i < 3; i++ {
+			_, err := newsAPI.GetNews(everything)
+			assert.Nil(t, err)
+		}
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/top-headlines?country=us")
+		assert.Equal(t, true, news_api.IsRateLimited(err))
+
+		res, err := http.Get(server.URL + "/v2/everything?q=apple&apiKey=" + server.APIKey)
+		assert.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, "60", res.Header.Get("Retry-After"))
+	})
+
+	t.Run("Truncated bodies and html pages", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Times: 1, Fault: newsapitest.Fault{Kind: newsapitest.FaultTruncatedBody}},
+			{Times: 2, Fault: newsapitest.Fault{Kind: newsapitest.FaultHTMLError}},
+		}})
+		_, err := newsAPI.GetNews(everything)
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, true, strings.HasPrefix(respErr.Reason, "invalid json"))
+
+		_, err = newsAPI.GetNews(everything)
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, "response is not json", respErr.Reason)
+		assert.Equal(t, http.StatusBadGateway, respErr.StatusCode)
+		assert.Equal(t, true, strings.Contains(respErr.Body, "502 Bad Gateway"))
+	})
+
+	t.Run("Connection resets", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Endpoint: news_api.EndpointSources, Fault: newsapitest.Fault{Kind: newsapitest.FaultConnectionReset}},
+		}})
+		_, err := newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.NotNil(t, err)
+		var apiErr *news_api.APIError
+		assert.Equal(t, false, errors.As(err, &apiErr))
+		_, err = newsAPI.GetNews(everything)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Fault: newsapitest.Fault{Latency: time.Second}},
+		}})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := newsAPI.GetNewsContext(ctx, everything)
+		assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Fault: newsapitest.Fault{Latency: 20 * time.Millisecond}},
+		}})
+		started := time.Now()
+		resp, err := newsAPI.GetNews(everything)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+		assert.Equal(t, true, time.Since(started) >= 20*time.Millisecond)
+	})
+
+	t.Run("Random faults are repeatable with a seed", func(t *testing.T) {
+		outcomes := func() []bool {
+			server.SetChaos(newsapitest.Chaos{Seed: 42, Rules: []newsapitest.Rule{
+				{Probability: 0.5, Fault: newsapitest.Fault{Kind: newsapitest.FaultServerError}},
+			}})
+			failed := []bool{}
+			for i := 0; i < 10; i++ {
+				_, err := newsAPI.GetNews(everything)
+				failed = append(failed, err != nil)
+			}
+			return failed
+		}
+		first := outcomes()
+		assert.Equal(t, first, outcomes())
+		assert.Contains(t, first, true)
+		assert.Contains(t, first, false)
+	})
+
+	t.Run("Retries recover from injected faults", func(t *testing.T) {
+		server.SetChaos(newsapitest.Chaos{Rules: []newsapitest.Rule{
+			{Times: 1, Fault: newsapitest.Fault{Kind: newsapitest.FaultConnectionReset}},
+			{Times: 2, Fault: newsapitest.Fault{Kind: newsapitest.FaultHTMLError}},
+		}})
+		retrying, err := server.Client(news_api.WithRetryPolicy(news_api.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+		assert.Nil(t, err)
+		resp, err := retrying.GetNews(everything)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+	})
+
+	server.SetChaos(newsapitest.Chaos{})
+}
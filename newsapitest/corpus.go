@@ -0,0 +1,52 @@
+package newsapitest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"os"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// Corpus is the set of articles and sources a Server answers from. Its JSON
+// form uses the same field names as NewsAPI responses:
+//
+//	{"articles": [...], "sources": [...]}
+type Corpus struct {
+	Articles []news_api.Articles `json:"articles"`
+	Sources  []news_api.Sources  `json:"sources"`
+}
+
+//go:embed fixtures/corpus.json
+var defaultCorpus []byte
+
+// DefaultCorpus returns the fixture corpus shipped with the package: a dozen
+// articles from early January 2024 and the sources they came from.
+func DefaultCorpus() Corpus {
+	corpus, err := LoadCorpus(bytes.NewReader(defaultCorpus))
+	if err != nil {
+		panic(err)
+	}
+	return corpus
+}
+
+// LoadCorpus reads a corpus in its JSON form.
+func LoadCorpus(r io.Reader) (Corpus, error) {
+	corpus := Corpus{}
+	if err := json.NewDecoder(r).Decode(&corpus); err != nil {
+		return Corpus{}, err
+	}
+	return corpus, nil
+}
+
+// LoadCorpusFile reads a corpus from a JSON file.
+func LoadCorpusFile(path string) (Corpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Corpus{}, err
+	}
+	defer file.Close()
+	return LoadCorpus(file)
+}
@@ -0,0 +1,55 @@
+package newsapitest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// matcher applies the q expressions of a request to articles with
+// news_api.Matcher. qInTitle adds a second expression that must also match.
+type matcher struct {
+	matchers []*news_api.Matcher
+}
+
+func newMatcher(q, searchIn string) (*matcher, *news_api.APIError) {
+	if len(q) > maxQLength {
+		return nil, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+			fmt.Sprintf("The q parameter is too long. It must be %d characters or less.", maxQLength))
+	}
+	fields := []news_api.SearchIn{}
+	for _, field := range splitList(searchIn) {
+		if !contains(searchIns, field) {
+			return nil, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+				fmt.Sprintf("The searchIn param is invalid. Possible options: %s.", strings.Join(searchIns, ", ")))
+		}
+		fields = append(fields, news_api.SearchIn(field))
+	}
+	compiled, err := news_api.CompileQ(q, fields...)
+	if err != nil {
+		return nil, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+			fmt.Sprintf("The q parameter is invalid: %s.", err))
+	}
+	return &matcher{matchers: []*news_api.Matcher{compiled}}, nil
+}
+
+func (m *matcher) and(other *matcher) *matcher {
+	m.matchers = append(m.matchers, other.matchers...)
+	return m
+}
+
+// match reports whether article satisfies every expression and how many
+// times their terms occur in the searched fields.
+func (m *matcher) match(article news_api.Articles) (int, bool) {
+	score := 0
+	for _, compiled := range m.matchers {
+		count, ok := compiled.Score(article)
+		if !ok {
+			return 0, false
+		}
+		score += count
+	}
+	return score, true
+}
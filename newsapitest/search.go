@@ -0,0 +1,394 @@
+package newsapitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// Plan holds the limits NewsAPI applies per subscription plan. A zero
+// MaxResults or History means no limit.
+type Plan struct {
+	Name string
+	// MaxResults is the number of results that can be paged through.
+	MaxResults int
+	// History is how far back from now articles can be requested.
+	History time.Duration
+}
+
+var (
+	PlanDeveloper = Plan{Name: "Developer", MaxResults: 100, History: news_api.DeveloperPlanHistory}
+	PlanBusiness  = Plan{Name: "Business", History: news_api.BusinessPlanHistory}
+)
+
+const (
+	maxPageSize   = 100
+	maxQLength    = 500
+	maxSources    = 20
+	dateOnlyStamp = "2006-01-02"
+)
+
+var (
+	languages  = news_api.ParamValues("language")
+	countries  = news_api.ParamValues("country")
+	categories = news_api.ParamValues("category")
+	sortBys    = news_api.ParamValues("sortBy")
+	searchIns  = news_api.ParamValues("searchIn")
+)
+
+// SearchEverything answers a /v2/everything request with params over the
+// corpus, applying plan limits relative to now. Articles are sorted by
+// publishedAt unless sortBy asks for relevancy, which ranks by the number of
+// query term matches, or popularity, which keeps corpus order.
+func (c Corpus) SearchEverything(params url.Values, plan Plan, now time.Time) (news_api.NewsResp, *news_api.APIError) {
+	q := params.Get("q")
+	sources := splitList(params.Get("sources"))
+	domains := splitList(params.Get("domains"))
+	if strings.TrimSpace(q) == "" && strings.TrimSpace(params.Get("qInTitle")) == "" && len(sources) == 0 && len(domains) == 0 {
+		return news_api.NewsResp{}, apiError(http.StatusBadRequest, news_api.CodeParametersMissing,
+			"Required parameters are missing, the scope of your search is too broad. Please set any of the following required parameters and try again: q, qInTitle, sources, domains.")
+	}
+	if apiErr := c.checkSources(sources); apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	matcher, apiErr := newMatcher(q, params.Get("searchIn"))
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	if qInTitle := params.Get("qInTitle"); qInTitle != "" {
+		titleMatcher, apiErr := newMatcher(qInTitle, "title")
+		if apiErr != nil {
+			return news_api.NewsResp{}, apiErr
+		}
+		matcher = matcher.and(titleMatcher)
+	}
+	language, apiErr := singleValue(params, "language", languages)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	sortBy, apiErr := singleValue(params, "sortBy", sortBys)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	from, to, apiErr := dateRange(params, plan, now)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	oldest := time.Time{}
+	if plan.History > 0 {
+		oldest = now.Add(-plan.History)
+	}
+	page, pageSize, apiErr := paging(params, plan)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+
+	sourceIndex := c.sourceIndex()
+	excludeDomains := splitList(params.Get("excludeDomains"))
+	matches := []scoredArticle{}
+	for i, article := range c.Articles {
+		published, err := parsePublishedAt(article.PublishedAt)
+		if err == nil && published.Before(oldest) {
+			continue
+		}
+		if !from.IsZero() && published.Before(from) || !to.IsZero() && published.After(to) {
+			continue
+		}
+		if len(sources) > 0 && !contains(sources, article.Source.IDOrEmpty()) {
+			continue
+		}
+		host := articleHost(article)
+		if len(domains) > 0 && !matchesDomain(host, domains) || matchesDomain(host, excludeDomains) {
+			continue
+		}
+		if language != "" && sourceIndex[article.Source.IDOrEmpty()].Language != language {
+			continue
+		}
+		score, ok := matcher.match(article)
+		if !ok {
+			continue
+		}
+		matches = append(matches, scoredArticle{article: article, published: published, score: score, position: i})
+	}
+	switch sortBy {
+	case "relevancy":
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].score != matches[j].score {
+				return matches[i].score > matches[j].score
+			}
+			return matches[i].published.After(matches[j].published)
+		})
+	case "popularity":
+	default:
+		sortByPublished(matches)
+	}
+	return pageOf(matches, page, pageSize), nil
+}
+
+// SearchTopHeadlines answers a /v2/top-headlines request with params over the
+// corpus. Country and category are taken from the source of each article.
+func (c Corpus) SearchTopHeadlines(params url.Values, plan Plan) (news_api.NewsResp, *news_api.APIError) {
+	sources := splitList(params.Get("sources"))
+	country, apiErr := singleValue(params, "country", countries)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	category, apiErr := singleValue(params, "category", categories)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	if len(sources) > 0 && (country != "" || category != "") {
+		return news_api.NewsResp{}, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+			"You cannot mix the sources parameter with the country or category parameters.")
+	}
+	q := params.Get("q")
+	if strings.TrimSpace(q) == "" && len(sources) == 0 && country == "" && category == "" {
+		return news_api.NewsResp{}, apiError(http.StatusBadRequest, news_api.CodeParametersMissing,
+			"Required parameters are missing. Please set any of the following parameters and try again: sources, q, language, country, category.")
+	}
+	if apiErr := c.checkSources(sources); apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	matcher, apiErr := newMatcher(q, "")
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+	page, pageSize, apiErr := paging(params, plan)
+	if apiErr != nil {
+		return news_api.NewsResp{}, apiErr
+	}
+
+	sourceIndex := c.sourceIndex()
+	matches := []scoredArticle{}
+	for i, article := range c.Articles {
+		sourceID := article.Source.IDOrEmpty()
+		if len(sources) > 0 && !contains(sources, sourceID) {
+			continue
+		}
+		if country != "" && sourceIndex[sourceID].Country != country {
+			continue
+		}
+		if category != "" && sourceIndex[sourceID].Category != category {
+			continue
+		}
+		if _, ok := matcher.match(article); !ok {
+			continue
+		}
+		published, _ := parsePublishedAt(article.PublishedAt)
+		matches = append(matches, scoredArticle{article: article, published: published, position: i})
+	}
+	sortByPublished(matches)
+	return pageOf(matches, page, pageSize), nil
+}
+
+// SearchSources answers a /v2/top-headlines/sources request with params.
+func (c Corpus) SearchSources(params url.Values) (news_api.SourcesResp, *news_api.APIError) {
+	filters := map[string]string{}
+	for _, param := range []struct {
+		name    string
+		allowed []string
+	}{{"category", categories}, {"language", languages}, {"country", countries}} {
+		value, apiErr := singleValue(params, param.name, param.allowed)
+		if apiErr != nil {
+			return news_api.SourcesResp{}, apiErr
+		}
+		filters[param.name] = value
+	}
+	sources := []news_api.Sources{}
+	for _, source := range c.Sources {
+		if filters["category"] != "" && source.Category != filters["category"] ||
+			filters["language"] != "" && source.Language != filters["language"] ||
+			filters["country"] != "" && source.Country != filters["country"] {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return news_api.SourcesResp{Status: "ok", Sources: sources}, nil
+}
+
+type scoredArticle struct {
+	article   news_api.Articles
+	published time.Time
+	score     int
+	position  int
+}
+
+func sortByPublished(matches []scoredArticle) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].published.After(matches[j].published)
+	})
+}
+
+func pageOf(matches []scoredArticle, page, pageSize int) news_api.NewsResp {
+	articles := []news_api.Articles{}
+	for i := (page - 1) * pageSize; i < page*pageSize && i < len(matches); i++ {
+		articles = append(articles, matches[i].article)
+	}
+	return news_api.NewsResp{Status: "ok", TotalResults: len(matches), Articles: articles}
+}
+
+func (c Corpus) sourceIndex() map[string]news_api.Sources {
+	index := map[string]news_api.Sources{}
+	for _, source := range c.Sources {
+		index[source.Id] = source
+	}
+	return index
+}
+
+func (c Corpus) checkSources(sources []string) *news_api.APIError {
+	if len(sources) > maxSources {
+		return apiError(http.StatusBadRequest, news_api.CodeSourcesTooMany,
+			"You have requested too many sources in a single request. Try splitting the request into 2 smaller requests.")
+	}
+	if len(c.Sources) == 0 {
+		return nil
+	}
+	index := c.sourceIndex()
+	for _, source := range sources {
+		if _, ok := index[source]; !ok {
+			return apiError(http.StatusBadRequest, news_api.CodeSourceDoesNotExist,
+				fmt.Sprintf("You have requested a source which does not exist: %s", source))
+		}
+	}
+	return nil
+}
+
+func singleValue(params url.Values, name string, allowed []string) (string, *news_api.APIError) {
+	value := params.Get(name)
+	if value == "" {
+		return "", nil
+	}
+	if !contains(allowed, value) {
+		return "", apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+			fmt.Sprintf("The %s param is invalid. Possible options: %s.", name, strings.Join(allowed, ", ")))
+	}
+	return value, nil
+}
+
+func paging(params url.Values, plan Plan) (int, int, *news_api.APIError) {
+	pageSize, apiErr := positiveInt(params, "pageSize", maxPageSize)
+	if apiErr != nil {
+		return 0, 0, apiErr
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	page, apiErr := positiveInt(params, "page", 1)
+	if apiErr != nil {
+		return 0, 0, apiErr
+	}
+	if plan.MaxResults > 0 && page*pageSize > plan.MaxResults {
+		return 0, 0, apiError(http.StatusUpgradeRequired, news_api.CodeMaximumResultsReached,
+			fmt.Sprintf("You have requested too many results. %s accounts are limited to a max of %d results. You are trying to request results %d to %d. Please upgrade to a paid plan if you need more results.",
+				plan.Name, plan.MaxResults, (page-1)*pageSize, page*pageSize))
+	}
+	return page, pageSize, nil
+}
+
+func positiveInt(params url.Values, name string, fallback int) (int, *news_api.APIError) {
+	value := strings.TrimSpace(params.Get(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		return 0, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+			fmt.Sprintf("The %s parameter must be a positive integer.", name))
+	}
+	return parsed, nil
+}
+
+// dateRange parses from and to, given as a date or an ISO 8601 date and
+// time. A date-only to covers the whole day.
+func dateRange(params url.Values, plan Plan, now time.Time) (time.Time, time.Time, *news_api.APIError) {
+	from, apiErr := parseDateParam(params, "from", false)
+	if apiErr != nil {
+		return time.Time{}, time.Time{}, apiErr
+	}
+	to, apiErr := parseDateParam(params, "to", true)
+	if apiErr != nil {
+		return time.Time{}, time.Time{}, apiErr
+	}
+	if plan.History > 0 {
+		oldest := now.Add(-plan.History)
+		if !from.IsZero() && from.Before(oldest) {
+			return time.Time{}, time.Time{}, apiError(http.StatusUpgradeRequired, news_api.CodeParameterInvalid,
+				fmt.Sprintf("You are trying to request results too far in the past. Your plan permits you to request articles as far back as %s, but you have requested %s. You may need to upgrade to a paid plan.",
+					oldest.Format(dateOnlyStamp), from.Format(dateOnlyStamp)))
+		}
+	}
+	return from, to, nil
+}
+
+func parseDateParam(params url.Values, name string, endOfDay bool) (time.Time, *news_api.APIError) {
+	value := params.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.Parse("2006-01-02T15:04:05", value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.Parse(dateOnlyStamp, value); err == nil {
+		if endOfDay {
+			return parsed.Add(24*time.Hour - time.Nanosecond), nil
+		}
+		return parsed, nil
+	}
+	return time.Time{}, apiError(http.StatusBadRequest, news_api.CodeParameterInvalid,
+		fmt.Sprintf("The %s parameter is in an invalid format. Use an ISO 8601 date or date and time, e.g. 2024-01-02 or 2024-01-02T15:04:05.", name))
+}
+
+func parsePublishedAt(value string) (time.Time, error) {
+	published, err := news_api.ParsePublishedTime(value)
+	return published.Time, err
+}
+
+func articleHost(article news_api.Articles) string {
+	parsed, err := url.Parse(article.Url)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "www."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func apiError(status int, code, message string) *news_api.APIError {
+	return &news_api.APIError{Code: code, Message: message, HTTPStatus: status}
+}
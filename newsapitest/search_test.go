@@ -0,0 +1,190 @@
+package newsapitest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func titles(resp news_api.NewsResp) []string {
+	list := []string{}
+	for _, article := range resp.Articles {
+		list = append(list, article.Title)
+	}
+	return list
+}
+
+func TestSearch(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	newsAPI, err := server.Client()
+	assert.Nil(t, err)
+
+	everything := func(t *testing.T, params map[string]interface{}) (news_api.NewsResp, error) {
+		qurl, err := news_api.ConstructQueryURL("everything", params)
+		assert.Nil(t, err)
+		return newsAPI.GetNews(qurl)
+	}
+
+	t.Run("q matches words in title, description and content", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "apple"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"Apple unveils new AI features for the iPhone",
+			"Apple and Google face UK app store inquiry",
+			"Apple shares slip as iPhone sales slow in China",
+		}, titles(resp))
+
+		resp, err = everything(t, map[string]interface{}{"q": "app"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, resp.TotalResults)
+	})
+
+	t.Run("q operators", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "apple -google"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.TotalResults)
+		resp, err = everything(t, map[string]interface{}{"q": "apple OR google"})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, resp.TotalResults)
+		resp, err = everything(t, map[string]interface{}{"q": `"app stores" AND NOT iphone`})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple and Google face UK app store inquiry"}, titles(resp))
+		resp, err = everything(t, map[string]interface{}{"q": "google OR (apple AND china)"})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+
+		_, err = newsAPI.GetNews(`https://newsapi.org/v2/everything?q=(apple`)
+		assert.ErrorIs(t, err, news_api.ErrParameterInvalid)
+	})
+
+	t.Run("searchIn restricts the fields", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "iphone", "searchIn": []string{"description"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple shares slip as iPhone sales slow in China"}, titles(resp))
+	})
+
+	t.Run("sources, domains and excludeDomains", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "prices OR shares", "sources": []string{"bloomberg"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Oil prices rise on Middle East supply concerns", "Apple shares slip as iPhone sales slow in China"}, titles(resp))
+
+		resp, err = newsAPI.GetNews("https://newsapi.org/v2/everything?domains=bbc.co.uk")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.TotalResults)
+
+		resp, err = everything(t, map[string]interface{}{"q": "apple", "excludeDomains": []string{"techcrunch.com"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple and Google face UK app store inquiry", "Apple shares slip as iPhone sales slow in China"}, titles(resp))
+	})
+
+	t.Run("from and to", func(t *testing.T) {
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/everything?domains=bloomberg.com,theverge.com,techcrunch.com&from=2024-01-09&to=2024-01-09")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Microsoft and OpenAI extend their partnership", "Oil prices rise on Middle East supply concerns"}, titles(resp))
+
+		resp, err = everything(t, map[string]interface{}{"q": "apple", "from": "2024-01-09T00:00:00Z"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.TotalResults)
+
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple&from=yesterday")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+	})
+
+	t.Run("language is a single code", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "intelligence", "language": []string{"fr"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"La France présente son plan pour l'intelligence artificielle"}, titles(resp))
+
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=intelligence&language=en,fr")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+	})
+
+	t.Run("sortBy", func(t *testing.T) {
+		resp, err := everything(t, map[string]interface{}{"q": "oil OR apple", "sortBy": "publishedAt"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Oil prices rise on Middle East supply concerns", resp.Articles[2].Title)
+		resp, err = everything(t, map[string]interface{}{"q": "oil OR apple", "sortBy": "relevancy"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Oil prices rise on Middle East supply concerns", resp.Articles[3].Title)
+	})
+
+	t.Run("everything needs a scope", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/everything?language=en")
+		assert.Equal(t, true, strings.Contains(err.Error(), "scope of your search is too broad"))
+		var apiErr *news_api.APIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, news_api.CodeParametersMissing, apiErr.Code)
+	})
+
+	t.Run("Unknown and too many sources", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/everything?sources=not-a-source")
+		assert.ErrorIs(t, err, news_api.ErrSourceDoesNotExist)
+		many := strings.TrimSuffix(strings.Repeat("bbc-news,", 21), ",")
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?sources=" + many)
+		assert.ErrorIs(t, err, news_api.ErrSourcesTooMany)
+	})
+
+	t.Run("top-headlines filters by source country and category", func(t *testing.T) {
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/top-headlines?country=us")
+		assert.Nil(t, err)
+		assert.Equal(t, 7, resp.TotalResults)
+		resp, err = newsAPI.GetNews("https://newsapi.org/v2/top-headlines?country=us&category=technology&q=ai")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Apple unveils new AI features for the iPhone", "Google tests AI search summaries in Europe"}, titles(resp))
+		resp, err = newsAPI.GetNews("https://newsapi.org/v2/top-headlines?sources=espn")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Lakers beat Celtics in overtime thriller"}, titles(resp))
+	})
+
+	t.Run("top-headlines rules", func(t *testing.T) {
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/top-headlines?sources=bbc-news&country=gb")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/top-headlines?pageSize=10")
+		assert.ErrorIs(t, err, news_api.ErrParametersMissing)
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/top-headlines?country=uk")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+	})
+
+	t.Run("sources endpoint filters", func(t *testing.T) {
+		resp, err := newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources?language=en")
+		assert.Nil(t, err)
+		assert.Equal(t, 6, len(resp.Sources))
+		resp, err = newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources?country=us&category=technology")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Sources))
+		_, err = newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources?category=tech")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+	})
+
+	t.Run("Developer plan limits", func(t *testing.T) {
+		developer := newsapitest.NewServer(newsapitest.WithPlan(newsapitest.PlanDeveloper),
+			newsapitest.WithClock(func() time.Time { return time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC) }))
+		defer developer.Close()
+		client, err := developer.Client()
+		assert.Nil(t, err)
+
+		resp, err := client.GetNews("https://newsapi.org/v2/everything?q=apple&pageSize=50&page=2")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(resp.Articles))
+		_, err = client.GetNews("https://newsapi.org/v2/everything?q=apple&pageSize=50&page=3")
+		assert.Equal(t, true, news_api.IsMaximumResultsReached(err))
+		_, err = client.GetNews("https://newsapi.org/v2/everything?q=apple&from=2023-12-01")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+		assert.Equal(t, true, strings.Contains(err.Error(), "too far in the past"))
+
+		late := newsapitest.NewServer(newsapitest.WithPlan(newsapitest.PlanDeveloper),
+			newsapitest.WithClock(func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }))
+		defer late.Close()
+		lateClient, err := late.Client()
+		assert.Nil(t, err)
+		resp, err = lateClient.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, resp.TotalResults)
+	})
+}
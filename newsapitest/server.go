@@ -0,0 +1,189 @@
+// Package newsapitest provides an in-process fake of the NewsAPI v2 HTTP API
+// for end-to-end tests of code built on news_api.
+package newsapitest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+)
+
+// DefaultAPIKey is the key a Server accepts unless WithAPIKey is used.
+const DefaultAPIKey = "newsapitest-key"
+
+// Server is a running fake NewsAPI. It serves /v2/everything,
+// /v2/top-headlines and /v2/top-headlines/sources from its corpus and checks
+// the X-Api-Key header, or the apiKey parameter, of every request.
+type Server struct {
+	*httptest.Server
+	APIKey string
+
+	mu       sync.Mutex
+	corpus   Corpus
+	plan     Plan
+	now      func() time.Time
+	requests int
+
+	chaos      Chaos
+	chaosRand  *rand.Rand
+	chaosCalls map[string]int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAPIKey sets the API key the server accepts.
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.APIKey = apiKey
+	}
+}
+
+// WithCorpus replaces DefaultCorpus as the data the server answers from.
+func WithCorpus(corpus Corpus) Option {
+	return func(s *Server) {
+		s.corpus = corpus
+	}
+}
+
+// WithPlan makes the server enforce the result and history limits of plan.
+// The default is PlanBusiness.
+func WithPlan(plan Plan) Option {
+	return func(s *Server) {
+		s.plan = plan
+	}
+}
+
+// WithClock sets the clock plan history limits are measured from.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{APIKey: DefaultAPIKey, corpus: DefaultCorpus(), plan: PlanBusiness, now: time.Now}
+	s.setChaos(Chaos{})
+	for _, opt := range opts {
+		opt(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/everything", s.authorized(s.withChaos(news_api.EndpointEverything, s.everything)))
+	mux.HandleFunc("/v2/top-headlines", s.authorized(s.withChaos(news_api.EndpointTopHeadlines, s.topHeadlines)))
+	mux.HandleFunc("/v2/top-headlines/sources", s.authorized(s.withChaos(news_api.EndpointSources, s.sources)))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "routeNotFound", "The requested route does not exist.")
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a news_api client that talks to the server with its API key.
+func (s *Server) Client(opts ...news_api.Option) (news_api.NewsAPIDAO, error) {
+	opts = append([]news_api.Option{news_api.WithBaseURL(s.URL)}, opts...)
+	return news_api.InitializeNewsAPI(s.APIKey, opts...)
+}
+
+// SetCorpus replaces the data the server answers from.
+func (s *Server) SetCorpus(corpus Corpus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.corpus = corpus
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) snapshot() Corpus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.corpus
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Only GET requests are supported.")
+			return
+		}
+		apiKey := r.Header.Get("X-Api-Key")
+		if apiKey == "" {
+			apiKey = r.URL.Query().Get("apiKey")
+		}
+		if apiKey == "" {
+			writeError(w, http.StatusUnauthorized, news_api.CodeAPIKeyMissing,
+				"Your API key is missing. Append this to the URL with the apiKey param, or use the x-api-key HTTP header.")
+			return
+		}
+		if apiKey != s.APIKey {
+			writeError(w, http.StatusUnauthorized, news_api.CodeAPIKeyInvalid,
+				"Your API key is invalid or incorrect. Check your key, or go to https://newsapi.org to create a free API key.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// articlesPayload and sourcesPayload mirror NewsAPI's success responses,
+// which always include totalResults and the result array.
+type articlesPayload struct {
+	Status       string              `json:"status"`
+	TotalResults int                 `json:"totalResults"`
+	Articles     []news_api.Articles `json:"articles"`
+}
+
+type sourcesPayload struct {
+	Status  string             `json:"status"`
+	Sources []news_api.Sources `json:"sources"`
+}
+
+func (s *Server) everything(w http.ResponseWriter, r *http.Request) {
+	resp, apiErr := s.snapshot().SearchEverything(r.URL.Query(), s.plan, s.now())
+	writeArticles(w, resp, apiErr)
+}
+
+func (s *Server) topHeadlines(w http.ResponseWriter, r *http.Request) {
+	resp, apiErr := s.snapshot().SearchTopHeadlines(r.URL.Query(), s.plan)
+	writeArticles(w, resp, apiErr)
+}
+
+func (s *Server) sources(w http.ResponseWriter, r *http.Request) {
+	resp, apiErr := s.snapshot().SearchSources(r.URL.Query())
+	if apiErr != nil {
+		writeError(w, apiErr.HTTPStatus, apiErr.Code, apiErr.Message)
+		return
+	}
+	writeJSON(w, http.StatusOK, sourcesPayload{Status: resp.Status, Sources: resp.Sources})
+}
+
+func writeArticles(w http.ResponseWriter, resp news_api.NewsResp, apiErr *news_api.APIError) {
+	if apiErr != nil {
+		writeError(w, apiErr.HTTPStatus, apiErr.Code, apiErr.Message)
+		return
+	}
+	writeJSON(w, http.StatusOK, articlesPayload{Status: resp.Status, TotalResults: resp.TotalResults, Articles: resp.Articles})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, news_api.NewsResp{Status: "error", Code: code, Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
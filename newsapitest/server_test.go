@@ -0,0 +1,111 @@
+package newsapitest_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+	"github.com/aekam27/newsAPIWrapper/newsapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	newsAPI, err := server.Client()
+	assert.Nil(t, err)
+
+	t.Run("Everything and top-headlines return corpus articles", func(t *testing.T) {
+		for _, queryType := range []string{"everything", "top-headlines"} {
+			qurl, err := news_api.ConstructQueryURL(queryType, map[string]interface{}{"q": "apple"})
+			assert.Nil(t, err)
+			resp, err := newsAPI.GetNews(qurl)
+			assert.Nil(t, err)
+			assert.Equal(t, "ok", resp.Status)
+			assert.Equal(t, 3, resp.TotalResults)
+		}
+	})
+
+	t.Run("Sources returns corpus sources", func(t *testing.T) {
+		resp, err := newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", resp.Status)
+		assert.Equal(t, "bbc-news", resp.Sources[0].Id)
+	})
+
+	t.Run("Pages through articles", func(t *testing.T) {
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/everything?q=the&pageSize=3&page=2")
+		assert.Nil(t, err)
+		assert.Equal(t, 8, resp.TotalResults)
+		assert.Equal(t, 3, len(resp.Articles))
+		resp, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=the&pageSize=3&page=3")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Articles))
+
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple&page=0")
+		assert.Equal(t, true, news_api.IsParameterInvalid(err))
+	})
+
+	t.Run("Checks the api key", func(t *testing.T) {
+		wrongKey, err := news_api.InitializeNewsAPI("wrong-key", news_api.WithBaseURL(server.URL))
+		assert.Nil(t, err)
+		_, err = wrongKey.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Equal(t, true, news_api.IsAPIKeyInvalid(err))
+		assert.EqualError(t, err, "Your API key is invalid or incorrect. Check your key, or go to https://newsapi.org to create a free API key.")
+		var apiErr *news_api.APIError
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatus)
+
+		res, err := http.Get(server.URL + "/v2/everything?q=apple")
+		assert.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		res, err = http.Get(server.URL + "/v2/everything?q=apple&apiKey=" + newsapitest.DefaultAPIKey)
+		assert.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Custom key and corpus", func(t *testing.T) {
+		custom := newsapitest.NewServer(newsapitest.WithAPIKey("secret"), newsapitest.WithCorpus(newsapitest.Corpus{
+			Articles: []news_api.Articles{{Title: "only article"}},
+		}))
+		defer custom.Close()
+		assert.Equal(t, "secret", custom.APIKey)
+		client, err := custom.Client()
+		assert.Nil(t, err)
+		resp, err := client.GetNews("https://newsapi.org/v2/everything?q=only")
+		assert.Nil(t, err)
+		assert.Equal(t, "only article", resp.Articles[0].Title)
+		sources, err := client.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(sources.Sources))
+		assert.Equal(t, 2, custom.Requests())
+	})
+
+	t.Run("Load corpus from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "corpus.json")
+		err := os.WriteFile(path, []byte(`{"articles":[{"title":"from file"}],"sources":[{"id":"abc"}]}`), 0o600)
+		assert.Nil(t, err)
+		corpus, err := newsapitest.LoadCorpusFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, "from file", corpus.Articles[0].Title)
+		assert.Equal(t, "abc", corpus.Sources[0].Id)
+
+		_, err = newsapitest.LoadCorpus(strings.NewReader("not json"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unknown routes", func(t *testing.T) {
+		_, err := newsAPI.GetNews(server.URL + "/v2/headlines?q=apple")
+		var apiErr *news_api.APIError
+		assert.Equal(t, true, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.HTTPStatus)
+	})
+}
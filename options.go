@@ -0,0 +1,130 @@
+package news_api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures the client returned by InitializeNewsAPI.
+type Option func(*newsAPI) error
+
+const defaultTimeout = 30 * time.Second
+
+// WithHTTPClient makes the client send requests through httpClient so that
+// connection pools can be shared. The client is copied, so later WithTimeout
+// and WithTransport options do not modify the caller's value.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(rep *newsAPI) error {
+		if httpClient == nil {
+			return errors.New("http client is nil")
+		}
+		rep.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithBaseURL replaces https://newsapi.org/v2/ as the prefix of every request
+// URL, for example to point the client at a local stand-in server. When the
+// base URL has no path, /v2/ is assumed.
+func WithBaseURL(baseURL string) Option {
+	return func(rep *newsAPI) error {
+		parsed, err := parseBaseURL(baseURL)
+		if err != nil {
+			return err
+		}
+		rep.baseURL = parsed.String()
+		return nil
+	}
+}
+
+// parseBaseURL parses an absolute base URL, assuming /v2/ when it has no
+// path and ending the path in a slash.
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.New("base url must be absolute")
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = "/v2/"
+	}
+	if !strings.HasSuffix(parsed.Path, "/") {
+		parsed.Path += "/"
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed, nil
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(rep *newsAPI) error {
+		rep.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithTimeout bounds the total time of a single HTTP request. A timeout of 0
+// means no timeout, as for http.Client. Without it requests time out after
+// 30 seconds unless WithHTTPClient supplies a client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(rep *newsAPI) error {
+		if timeout < 0 {
+			return errors.New("timeout should be greaterthan equalto 0")
+		}
+		rep.timeout = &timeout
+		return nil
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send requests, for example
+// one configured with a corporate proxy.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(rep *newsAPI) error {
+		if transport == nil {
+			return errors.New("transport is nil")
+		}
+		rep.transport = transport
+		return nil
+	}
+}
+
+func (rep *newsAPI) buildHTTPClient() *http.Client {
+	client := &http.Client{Timeout: defaultTimeout}
+	if rep.httpClient != nil {
+		copied := *rep.httpClient
+		client = &copied
+	}
+	if rep.timeout != nil {
+		client.Timeout = *rep.timeout
+	}
+	if rep.transport != nil {
+		client.Transport = rep.transport
+	}
+	return client
+}
+
+// resolveURL moves URLs built for the default base URL onto the configured
+// one and resolves relative URLs such as "everything?q=apple" against it.
+func (rep *newsAPI) resolveURL(apiURL string) string {
+	if rep.baseURL == defaultBaseURL {
+		return apiURL
+	}
+	if strings.HasPrefix(apiURL, defaultBaseURL) {
+		return rep.baseURL + strings.TrimPrefix(apiURL, defaultBaseURL)
+	}
+	parsed, err := url.Parse(apiURL)
+	if err != nil || parsed.IsAbs() {
+		return apiURL
+	}
+	base, err := url.Parse(rep.baseURL)
+	if err != nil {
+		return apiURL
+	}
+	return base.ResolveReference(parsed).String()
+}
@@ -0,0 +1,121 @@
+package news_api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingTransport struct {
+	calls int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	var (
+		lastPath      string
+		lastUserAgent string
+		lastAPIKey    string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath = r.URL.RequestURI()
+		lastUserAgent = r.Header.Get("User-Agent")
+		lastAPIKey = r.Header.Get("X-Api-Key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok","totalResults":0,"articles":[],"sources":[]}`))
+	}))
+	defer server.Close()
+
+	t.Run("Base url and user agent", func(t *testing.T) {
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx",
+			news_api.WithBaseURL(server.URL), news_api.WithUserAgent("news-bot/1.0"))
+		assert.Nil(t, err)
+		qurl, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple"})
+		assert.Nil(t, err)
+		resp, err := newsAPI.GetNews(qurl)
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", resp.Status)
+		assert.Equal(t, "/v2/everything?q=apple", lastPath)
+		assert.Equal(t, "news-bot/1.0", lastUserAgent)
+		assert.Equal(t, "xxxxxxxxxxxxxxxxxxxxxxxx", lastAPIKey)
+
+		_, err = newsAPI.GetSources("top-headlines/sources?country=us")
+		assert.Nil(t, err)
+		assert.Equal(t, "/v2/top-headlines/sources?country=us", lastPath)
+	})
+
+	t.Run("Base url keeps a custom path", func(t *testing.T) {
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL+"/proxy/v2"))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/top-headlines?q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, "/proxy/v2/top-headlines?q=apple", lastPath)
+	})
+
+	t.Run("Invalid base url", func(t *testing.T) {
+		_, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL("newsapi.local"))
+		assert.EqualError(t, err, "base url must be absolute")
+	})
+
+	t.Run("Transport and http client are reused", func(t *testing.T) {
+		transport := &countingTransport{}
+		httpClient := &http.Client{Timeout: time.Minute}
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithHTTPClient(httpClient),
+			news_api.WithTransport(transport), news_api.WithTimeout(time.Second), news_api.WithBaseURL(server.URL))
+		assert.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&transport.calls))
+		assert.Equal(t, time.Minute, httpClient.Timeout)
+		assert.Nil(t, httpClient.Transport)
+	})
+
+	t.Run("Timeout bounds slow requests", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}))
+		defer slow.Close()
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithTimeout(50*time.Millisecond))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews(slow.URL + "/v2/everything?q=apple")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Zero timeout means no timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		defer slow.Close()
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx",
+			news_api.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}), news_api.WithTimeout(0))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews(slow.URL + "/v2/everything?q=apple")
+		assert.Nil(t, err)
+		_, err = news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithTimeout(-time.Second))
+		assert.EqualError(t, err, "timeout should be greaterthan equalto 0")
+	})
+
+	t.Run("Nil options are rejected", func(t *testing.T) {
+		_, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithHTTPClient(nil))
+		assert.EqualError(t, err, "http client is nil")
+		_, err = news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithTransport(nil))
+		assert.EqualError(t, err, "transport is nil")
+	})
+}
@@ -0,0 +1,143 @@
+package news_api
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageIterator walks the result pages of an everything or top-headlines URL.
+// It stops after the page that brings the number of articles seen up to
+// TotalResults, on an empty page, at the caller's page cap, or when NewsAPI
+// answers with maximumResultsReached. Only the last case is not reported by
+// Err, since it is how NewsAPI ends paging on plans with a result limit.
+//
+//	it := news_api.Pages(ctx, newsAPI, qurl, 0)
+//	for it.Next() {
+//		articles = append(articles, it.Articles()...)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	ctx      context.Context
+	dao      NewsAPIDAO
+	url      *url.URL
+	page     int
+	pageSize int
+	maxPages int
+	fetched  int
+	seen     int
+	resp     NewsResp
+	err      error
+	done     bool
+}
+
+// Pages returns an iterator over the pages of apiURL starting at its page
+// parameter, or 1. A maxPages above 0 caps the number of pages fetched.
+func Pages(ctx context.Context, dao NewsAPIDAO, apiURL string, maxPages int) *PageIterator {
+	it := &PageIterator{ctx: ctx, dao: dao, maxPages: maxPages, page: int(defaultPage)}
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return it
+	}
+	it.url = parsed
+	if page, err := strconv.Atoi(parsed.Query().Get("page")); err == nil && page > 0 {
+		it.page = page
+	}
+	if size, err := strconv.Atoi(parsed.Query().Get("pageSize")); err == nil && size > 0 {
+		it.pageSize = size
+	}
+	return it
+}
+
+// Next fetches the next page and reports whether one is available.
+func (it *PageIterator) Next() bool {
+	if it.done || (it.maxPages > 0 && it.fetched >= it.maxPages) {
+		it.done = true
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	resp, err := it.dao.GetNewsContext(it.ctx, it.pageURL(it.page))
+	if err != nil {
+		if !IsMaximumResultsReached(err) {
+			it.err = err
+		}
+		it.done = true
+		return false
+	}
+	if len(resp.Articles) == 0 {
+		it.done = true
+		return false
+	}
+	if it.fetched == 0 {
+		// Count the articles on the pages before the starting page, which
+		// are full unless the URL says otherwise.
+		pageSize := it.pageSize
+		if pageSize == 0 {
+			pageSize = len(resp.Articles)
+		}
+		it.seen = (it.page - 1) * pageSize
+	}
+	it.resp = resp
+	it.fetched++
+	it.seen += len(resp.Articles)
+	it.page++
+	if it.seen >= resp.TotalResults {
+		it.done = true
+	}
+	return true
+}
+
+// Page returns the response of the current page.
+func (it *PageIterator) Page() NewsResp {
+	return it.resp
+}
+
+// PageNumber returns the page parameter of the current page.
+func (it *PageIterator) PageNumber() int {
+	return it.page - 1
+}
+
+// Articles returns the articles of the current page.
+func (it *PageIterator) Articles() []Articles {
+	return it.resp.Articles
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+func (it *PageIterator) pageURL(page int) string {
+	return withPage(it.url, page)
+}
+
+// withPage returns u with its page parameter set to page. The other
+// parameters keep their order and encoding.
+func withPage(u *url.URL, page int) string {
+	pageURL := *u
+	params := []string{}
+	if pageURL.RawQuery != "" {
+		params = strings.Split(pageURL.RawQuery, "&")
+	}
+	replaced := false
+	for i, param := range params {
+		if param == "page" || strings.HasPrefix(param, "page=") {
+			params[i] = "page=" + strconv.Itoa(page)
+			replaced = true
+		}
+	}
+	if !replaced {
+		params = append(params, "page="+strconv.Itoa(page))
+	}
+	pageURL.RawQuery = strings.Join(params, "&")
+	return pageURL.String()
+}
@@ -0,0 +1,131 @@
+package news_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pagingServer serves total numbered articles in pages of the requested
+// pageSize. Pages past resultLimit articles answer maximumResultsReached.
+func pagingServer(total, resultLimit int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if page < 1 {
+			page = 1
+		}
+		if pageSize < 1 {
+			pageSize = 100
+		}
+		if resultLimit > 0 && page*pageSize > resultLimit {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			w.Write([]byte(`{"status":"error","code":"maximumResultsReached","message":"limit"}`))
+			return
+		}
+		resp := news_api.NewsResp{Status: "ok", TotalResults: total, Articles: []news_api.Articles{}}
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			resp.Articles = append(resp.Articles, news_api.Articles{Title: fmt.Sprintf("article %d", i)})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestPages(t *testing.T) {
+	var calls int32
+	server := pagingServer(25, 0, &calls)
+	defer server.Close()
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL))
+	assert.Nil(t, err)
+
+	t.Run("Walks every page up to TotalResults", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		it := news_api.Pages(context.Background(), newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", 0)
+		titles := []string{}
+		pages := []int{}
+		for it.Next() {
+			pages = append(pages, it.PageNumber())
+			for _, article := range it.Articles() {
+				titles = append(titles, article.Title)
+			}
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, []int{1, 2, 3}, pages)
+		assert.Equal(t, 25, len(titles))
+		assert.Equal(t, "article 24", titles[24])
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Starts at the page of the url and honors the cap", func(t *testing.T) {
+		it := news_api.Pages(context.Background(), newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=5&page=2", 2)
+		pages := []int{}
+		for it.Next() {
+			pages = append(pages, it.PageNumber())
+			assert.Equal(t, 25, it.Page().TotalResults)
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, []int{2, 3}, pages)
+	})
+
+	t.Run("Starting past page 1 stops at the last page", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		it := news_api.Pages(context.Background(), newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10&page=2", 0)
+		pages := []int{}
+		for it.Next() {
+			pages = append(pages, it.PageNumber())
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, []int{2, 3}, pages)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Stops cleanly on maximumResultsReached", func(t *testing.T) {
+		var limitedCalls int32
+		limited := pagingServer(500, 30, &limitedCalls)
+		defer limited.Close()
+		limitedAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(limited.URL))
+		assert.Nil(t, err)
+		it := news_api.Pages(context.Background(), limitedAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", 0)
+		count := 0
+		for it.Next() {
+			count += len(it.Articles())
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, 30, count)
+	})
+
+	t.Run("Respects context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		it := news_api.Pages(ctx, newsAPI, "https://newsapi.org/v2/everything?q=apple&pageSize=10", 0)
+		assert.Equal(t, true, it.Next())
+		cancel()
+		assert.Equal(t, false, it.Next())
+		assert.Equal(t, true, errors.Is(it.Err(), context.Canceled))
+	})
+
+	t.Run("Other errors are reported", func(t *testing.T) {
+		badKey := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"bad key"}`))
+		}))
+		defer badKey.Close()
+		badAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(badKey.URL))
+		assert.Nil(t, err)
+		it := news_api.Pages(context.Background(), badAPI, "https://newsapi.org/v2/everything?q=apple", 0)
+		assert.Equal(t, false, it.Next())
+		assert.Equal(t, true, news_api.IsAPIKeyInvalid(it.Err()))
+	})
+}
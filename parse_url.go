@@ -0,0 +1,164 @@
+package news_api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQueryURL reads a NewsAPI everything, top-headlines or sources URL,
+// such as one built by ConstructQueryURL or BuildQueryURL, back into an
+// EverythingQuery, TopHeadlinesQuery or SourcesQuery. The URL must be under
+// https://newsapi.org/v2/, or the base given to WithQueryBaseURL, and
+// relative URLs are resolved against it. q is decoded, lists are split on
+// commas and dates and paging are parsed, so BuildQueryURL gives an
+// equivalent URL. The apiKey parameter is ignored. Unknown parameters,
+// parameters the endpoint does not accept, extra values of a single valued
+// parameter and unparsable values are dropped, or rejected by
+// WithStrictValidation.
+func ParseQueryURL(rawURL string, opts ...QueryOption) (Query, error) {
+	options := newQueryOptions(opts)
+	endpoint, values, err := SplitQueryURL(rawURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == "" {
+		parsed, _ := url.Parse(strings.TrimSpace(rawURL))
+		return nil, errors.New("not a NewsAPI endpoint: " + parsed.Path)
+	}
+
+	d := &diagnostics{}
+	query := queryFor(endpoint, fieldsFromValues(values, d), d)
+
+	logQuery(context.Background(), options.logger, endpoint, d.fields, options.strict)
+	if options.strict && len(d.fields) > 0 {
+		return nil, &ValidationError{Fields: d.fields}
+	}
+	if options.warnings != nil {
+		*options.warnings = append(*options.warnings, d.fields...)
+	}
+	return query, nil
+}
+
+// SplitQueryURL returns the endpoint rawURL points at and its parameters as
+// sent, without validating them. The endpoint is "" when the path is not a
+// NewsAPI endpoint. Like ParseQueryURL it accepts URLs under
+// https://newsapi.org/v2/, or the base given to WithQueryBaseURL, and URLs on
+// any other host are an error.
+func SplitQueryURL(rawURL string, opts ...QueryOption) (string, url.Values, error) {
+	options := newQueryOptions(opts)
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", nil, err
+	}
+	base, err := parseBaseURL(options.baseURL)
+	if err != nil {
+		return "", nil, err
+	}
+	if parsed.Host != "" && !strings.EqualFold(parsed.Host, base.Host) {
+		return "", nil, errors.New("not a NewsAPI host: " + parsed.Host)
+	}
+	values, err := url.ParseQuery(parsed.RawQuery)
+	if err != nil {
+		return "", nil, err
+	}
+	return endpointFromURL(parsed, base), values, nil
+}
+
+// fieldsFromValues reads URL parameters into fields, reporting unknown
+// parameters and unparsable values to d. The apiKey parameter is ignored.
+func fieldsFromValues(values url.Values, d *diagnostics) queryFields {
+	fields := queryFields{}
+	for _, key := range sortedKeys(values) {
+		switch key {
+		case "q":
+			fields.Q = values.Get(key)
+		case "searchIn":
+			fields.SearchIn = splitValues[SearchIn](values[key])
+		case "sources":
+			fields.Sources = splitValues[string](values[key])
+		case "domains":
+			fields.Domains = splitValues[string](values[key])
+		case "excludeDomains":
+			fields.ExcludeDomains = splitValues[string](values[key])
+		case "country":
+			fields.Country = splitValues[Country](values[key])
+		case "category":
+			fields.Category = splitValues[Category](values[key])
+		case "language":
+			fields.Language = splitValues[Language](values[key])
+		case "sortBy":
+			fields.SortBy = SortBy(values.Get(key))
+		case "from":
+			fields.From = urlTimeParam(values, key, d)
+		case "to":
+			fields.To = urlTimeParam(values, key, d)
+		case "pageSize":
+			fields.PageSize = urlIntParam(values, key, d)
+		case "page":
+			fields.Page = urlIntParam(values, key, d)
+		case "apiKey":
+		default:
+			d.add(key, values.Get(key), "unknown parameter")
+		}
+	}
+	return fields
+}
+
+// sortedKeys returns the keys of values in queryParamOrder followed by any
+// unknown keys in sorted order.
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for _, key := range queryParamOrder {
+		if _, ok := values[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	unknown := []string{}
+	for key := range values {
+		if !contains(queryParamOrder, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return append(keys, unknown...)
+}
+
+// splitValues splits every value of a repeated parameter on commas.
+func splitValues[T ~string](values []string) []T {
+	list := []T{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, T(item))
+			}
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+func urlTimeParam(values url.Values, key string, d *diagnostics) time.Time {
+	value := strings.TrimSpace(values.Get(key))
+	parsed, ok := parseQueryDate(value, key == "to")
+	if !ok && value != "" {
+		d.add(key, value, "not a timestamp or date")
+	}
+	return parsed
+}
+
+func urlIntParam(values url.Values, key string, d *diagnostics) int64 {
+	value := strings.TrimSpace(values.Get(key))
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		d.add(key, value, "not an integer")
+		return 0
+	}
+	return parsed
+}
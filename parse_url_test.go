@@ -0,0 +1,115 @@
+package news_api_test
+
+import (
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryURL(t *testing.T) {
+	t.Run("Everything URL", func(t *testing.T) {
+		query, err := news_api.ParseQueryURL("https://newsapi.org/v2/everything?q=%22climate+change%22+AND+%28policy+OR+summit%29" +
+			"&searchIn=title,description&sources=bbc-news,reuters&domains=bbc.co.uk&excludeDomains=example.com" +
+			"&from=2024-01-02T00:00:00Z&to=2024-01-09&language=en&sortBy=popularity&pageSize=20&page=3&apiKey=secret")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.EverythingQuery{
+			Q:              `"climate change" AND (policy OR summit)`,
+			SearchIn:       []news_api.SearchIn{news_api.SearchInTitle, news_api.SearchInDescription},
+			Sources:        []string{"bbc-news", "reuters"},
+			Domains:        []string{"bbc.co.uk"},
+			ExcludeDomains: []string{"example.com"},
+			From:           time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			To:             time.Date(2024, 1, 9, 23, 59, 59, 0, time.UTC),
+			Language:       news_api.LanguageEN,
+			SortBy:         news_api.SortByPopularity,
+			PageSize:       20,
+			Page:           3,
+		}, query)
+	})
+
+	t.Run("Top headlines and sources URLs", func(t *testing.T) {
+		query, err := news_api.ParseQueryURL("https://newsapi.org/v2/top-headlines?country=us&category=business&q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.TopHeadlinesQuery{Q: "apple", Country: "us",
+			Category: news_api.CategoryBusiness}, query)
+
+		warnings := []news_api.FieldError{}
+		query, err = news_api.ParseQueryURL("http://localhost:8080/v2/top-headlines/sources/?q=apple&language=en&language=fr",
+			news_api.WithQueryBaseURL("http://localhost:8080"), news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.SourcesQuery{Language: news_api.LanguageEN}, query)
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "q", Value: "apple", Reason: "not supported by top-headlines/sources"},
+			{Field: "language", Value: "fr", Reason: "accepts a single value, using en"},
+		}, warnings)
+
+		query, err = news_api.ParseQueryURL("https://newsapi.org/v2/top-headlines/sources?")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.SourcesQuery{}, query)
+	})
+
+	t.Run("Built URLs parse back to an equivalent URL", func(t *testing.T) {
+		urls := []string{}
+		qurl, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple & pear", "from": "2023-01-02T00:00:00Z",
+			"to": "2023-01-15T15:04:05Z", "sortBy": "publishedAt", "pageSize": 10, "page": 1, "searchIn": []string{"title", "content"},
+			"domains": []string{"bbc.co.uk", "techcrunch.com"}, "language": "en"})
+		assert.Nil(t, err)
+		urls = append(urls, qurl)
+		qurl, err = news_api.ConstructQueryURL("top-headlines", map[string]interface{}{"sources": []string{"bbc-news", "cnn"}, "pageSize": 50})
+		assert.Nil(t, err)
+		urls = append(urls, qurl)
+		qurl, err = news_api.BuildQueryURL(news_api.SourcesQuery{Country: "gb", Category: news_api.CategoryTechnology})
+		assert.Nil(t, err)
+		urls = append(urls, qurl)
+
+		for _, qurl := range urls {
+			query, err := news_api.ParseQueryURL(qurl)
+			assert.Nil(t, err, qurl)
+			rebuilt, err := news_api.BuildQueryURL(query)
+			assert.Nil(t, err)
+			assert.Equal(t, qurl, rebuilt)
+		}
+	})
+
+	t.Run("Invalid values are dropped or rejected", func(t *testing.T) {
+		qurl := "https://newsapi.org/v2/everything?q=apple&from=yesterday&pageSize=ten&qInTitle=pie"
+		warnings := []news_api.FieldError{}
+		query, err := news_api.ParseQueryURL(qurl, news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.EverythingQuery{Q: "apple"}, query)
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "from", Value: "yesterday", Reason: "not a timestamp or date"},
+			{Field: "pageSize", Value: "ten", Reason: "not an integer"},
+			{Field: "qInTitle", Value: "pie", Reason: "unknown parameter"},
+		}, warnings)
+
+		_, err = news_api.ParseQueryURL(qurl, news_api.WithStrictValidation())
+		var validationErr *news_api.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, 3, len(validationErr.Fields))
+
+		_, err = news_api.ParseQueryURL("https://newsapi.org/v2/headlines?q=apple")
+		assert.EqualError(t, err, "not a NewsAPI endpoint: /v2/headlines")
+		_, err = news_api.ParseQueryURL("https://example.com/foo/everything?q=x")
+		assert.EqualError(t, err, "not a NewsAPI host: example.com")
+		_, err = news_api.ParseQueryURL("https://newsapi.org/foo/everything?q=x")
+		assert.EqualError(t, err, "not a NewsAPI endpoint: /foo/everything")
+		_, err = news_api.ParseQueryURL("http://localhost:8080/v2/everything?q=x", news_api.WithQueryBaseURL("http://localhost:8081/v2/"))
+		assert.EqualError(t, err, "not a NewsAPI host: localhost:8080")
+		endpoint, params, err := news_api.SplitQueryURL("https://newsapi.org/v2/everything?q=apple&pageSize=ten")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.EndpointEverything, endpoint)
+		assert.Equal(t, "ten", params.Get("pageSize"))
+		endpoint, _, err = news_api.SplitQueryURL("https://newsapi.org/v2/headlines")
+		assert.Nil(t, err)
+		assert.Equal(t, "", endpoint)
+		query, err = news_api.ParseQueryURL("/v2/everything?q=x")
+		assert.Nil(t, err)
+		assert.Equal(t, news_api.EverythingQuery{Q: "x"}, query)
+		_, err = news_api.ParseQueryURL("https://newsapi.org/v2/everything?q=%zz")
+		assert.NotNil(t, err)
+	})
+}
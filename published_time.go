@@ -0,0 +1,96 @@
+package news_api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// publishedTimeLayouts are the publishedAt forms seen in NewsAPI responses,
+// most common first. Layouts without a zone are read as UTC.
+var publishedTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// PublishedTime is a parsed publishedAt value. The embedded Time is in UTC
+// and String returns the value exactly as NewsAPI sent it.
+type PublishedTime struct {
+	time.Time
+	raw string
+}
+
+// ParsePublishedTime parses value in any of the RFC3339 variants NewsAPI
+// uses, with or without fractional seconds, zone offsets or the T separator.
+func ParsePublishedTime(value string) (PublishedTime, error) {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) > 10 && trimmed[10] == 't' {
+		trimmed = trimmed[:10] + "T" + trimmed[11:]
+	}
+	if strings.HasSuffix(trimmed, "z") {
+		trimmed = strings.TrimSuffix(trimmed, "z") + "Z"
+	}
+	for _, layout := range publishedTimeLayouts {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			return PublishedTime{Time: t.UTC(), raw: value}, nil
+		}
+	}
+	return PublishedTime{}, fmt.Errorf("unable to parse published time %q", value)
+}
+
+// PublishedTime parses the article's PublishedAt.
+func (a Articles) PublishedTime() (PublishedTime, error) {
+	return ParsePublishedTime(a.PublishedAt)
+}
+
+// String returns the original publishedAt string, or the time in RFC3339
+// when the value was not parsed from one.
+func (p PublishedTime) String() string {
+	if p.raw != "" {
+		return p.raw
+	}
+	if p.IsZero() {
+		return ""
+	}
+	return p.UTC().Format(time.RFC3339Nano)
+}
+
+// MarshalJSON writes the original string so that decoding and encoding a
+// response leaves publishedAt unchanged.
+func (p PublishedTime) MarshalJSON() ([]byte, error) {
+	if p.raw == "" && p.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON parses a publishedAt string. null and "" give the zero value.
+func (p *PublishedTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*p = PublishedTime{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		*p = PublishedTime{}
+		return nil
+	}
+	parsed, err := ParsePublishedTime(value)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
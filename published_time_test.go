@@ -0,0 +1,84 @@
+package news_api_test
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishedTime(t *testing.T) {
+	want := time.Date(2024, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	t.Run("Tolerated formats", func(t *testing.T) {
+		for _, value := range []string{
+			"2024-01-09T14:30:00Z",
+			"2024-01-09T14:30:00.000Z",
+			"2024-01-09T14:30:00.0000000Z",
+			"2024-01-09T15:30:00+01:00",
+			"2024-01-09T15:30:00+0100",
+			"2024-01-09T14:30:00",
+			"2024-01-09 14:30:00",
+			"2024-01-09T14:30Z",
+			"2024-01-09t14:30:00z",
+			"Tue, 09 Jan 2024 14:30:00 GMT",
+		} {
+			published, err := news_api.ParsePublishedTime(value)
+			assert.Nil(t, err, value)
+			assert.Equal(t, true, published.Equal(want), value)
+			assert.Equal(t, time.UTC, published.Location(), value)
+			assert.Equal(t, value, published.String())
+		}
+		published, err := news_api.ParsePublishedTime("2024-01-09T14:30:00.123456Z")
+		assert.Nil(t, err)
+		assert.Equal(t, 123456000, published.Nanosecond())
+		published, err = news_api.ParsePublishedTime("2024-01-09")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), published.Time)
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		_, err := news_api.ParsePublishedTime("last tuesday")
+		assert.EqualError(t, err, `unable to parse published time "last tuesday"`)
+		_, err = news_api.Articles{}.PublishedTime()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Articles sort by parsed time", func(t *testing.T) {
+		articles := []news_api.Articles{
+			{Title: "b", PublishedAt: "2024-01-09T15:30:00+01:00"},
+			{Title: "c", PublishedAt: "2024-01-09T14:30:00.5Z"},
+			{Title: "a", PublishedAt: "2024-01-09T14:29:59Z"},
+		}
+		sort.Slice(articles, func(i, j int) bool {
+			ti, _ := articles[i].PublishedTime()
+			tj, _ := articles[j].PublishedTime()
+			return ti.Before(tj.Time)
+		})
+		assert.Equal(t, "a", articles[0].Title)
+		assert.Equal(t, "c", articles[2].Title)
+	})
+
+	t.Run("Json round trip", func(t *testing.T) {
+		var decoded struct {
+			PublishedAt news_api.PublishedTime `json:"publishedAt"`
+			Missing     news_api.PublishedTime `json:"missing"`
+		}
+		input := `{"publishedAt":"2024-01-09T15:30:00.000+01:00","missing":null}`
+		assert.Nil(t, json.Unmarshal([]byte(input), &decoded))
+		assert.Equal(t, true, decoded.PublishedAt.Equal(want))
+		assert.Equal(t, true, decoded.Missing.IsZero())
+		encoded, err := json.Marshal(decoded)
+		assert.Nil(t, err)
+		assert.Equal(t, input, string(encoded))
+
+		encoded, err = json.Marshal(news_api.PublishedTime{Time: want})
+		assert.Nil(t, err)
+		assert.Equal(t, `"2024-01-09T14:30:00Z"`, string(encoded))
+		assert.NotNil(t, json.Unmarshal([]byte(`"soon"`), &decoded.PublishedAt))
+	})
+}
@@ -0,0 +1,373 @@
+package news_api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr is a node of a q expression. Build one with Term, Phrase, And, Or,
+// Not, Must and Exclude, render it with RenderQ and read an existing q string
+// back with ParseQ. String renders the expression without validating it.
+type Expr interface {
+	String() string
+	render(b *strings.Builder) error
+}
+
+// TermExpr is a single word.
+type TermExpr struct{ Word string }
+
+// PhraseExpr is an exact phrase, rendered in double quotes.
+type PhraseExpr struct{ Text string }
+
+// AndExpr matches when all of Exprs match.
+type AndExpr struct{ Exprs []Expr }
+
+// OrExpr matches when any of Exprs matches.
+type OrExpr struct{ Exprs []Expr }
+
+// NotExpr matches when Expr does not, rendered with the NOT keyword.
+type NotExpr struct{ Expr Expr }
+
+// MustExpr requires Expr, rendered with a leading +.
+type MustExpr struct{ Expr Expr }
+
+// ExcludeExpr excludes Expr, rendered with a leading -.
+type ExcludeExpr struct{ Expr Expr }
+
+// Term returns a single word. A word NewsAPI would not read as one term, such
+// as one with spaces or an AND/OR/NOT keyword, is rendered as a phrase.
+func Term(word string) Expr { return TermExpr{Word: word} }
+
+// Phrase returns an exact phrase.
+func Phrase(text string) Expr { return PhraseExpr{Text: text} }
+
+// And returns an expression that matches when all of exprs match.
+func And(exprs ...Expr) Expr { return AndExpr{Exprs: exprs} }
+
+// Or returns an expression that matches when any of exprs matches.
+func Or(exprs ...Expr) Expr { return OrExpr{Exprs: exprs} }
+
+// Not negates expr with the NOT keyword.
+func Not(expr Expr) Expr { return NotExpr{Expr: expr} }
+
+// Must marks expr as required with a leading +.
+func Must(expr Expr) Expr { return MustExpr{Expr: expr} }
+
+// Exclude marks expr as excluded with a leading -.
+func Exclude(expr Expr) Expr { return ExcludeExpr{Expr: expr} }
+
+// RenderQ renders expr as a q parameter value and checks it against the 500
+// character limit.
+func RenderQ(expr Expr) (string, error) {
+	if expr == nil {
+		return "", errors.New("query string is required")
+	}
+	b := strings.Builder{}
+	if err := expr.render(&b); err != nil {
+		return "", err
+	}
+	q := b.String()
+	if len(q) > int(maxQstringLength) {
+		return "", errors.New("query string length should be lessthan equalto 500")
+	}
+	return q, nil
+}
+
+func (e TermExpr) String() string    { return exprString(e) }
+func (e PhraseExpr) String() string  { return exprString(e) }
+func (e AndExpr) String() string     { return exprString(e) }
+func (e OrExpr) String() string      { return exprString(e) }
+func (e NotExpr) String() string     { return exprString(e) }
+func (e MustExpr) String() string    { return exprString(e) }
+func (e ExcludeExpr) String() string { return exprString(e) }
+
+func exprString(e Expr) string {
+	b := strings.Builder{}
+	e.render(&b)
+	return b.String()
+}
+
+func (e TermExpr) render(b *strings.Builder) error {
+	word := strings.TrimSpace(e.Word)
+	if word == "" {
+		return errors.New("query term is empty")
+	}
+	if strings.ContainsRune(word, '"') {
+		return fmt.Errorf("query term %q cannot contain double quotes", e.Word)
+	}
+	if needsQuotes(word) {
+		return PhraseExpr{Text: word}.render(b)
+	}
+	b.WriteString(word)
+	return nil
+}
+
+func (e PhraseExpr) render(b *strings.Builder) error {
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if text == "" {
+		return errors.New("query phrase is empty")
+	}
+	if strings.ContainsRune(text, '"') {
+		return fmt.Errorf("query phrase %q cannot contain double quotes", e.Text)
+	}
+	b.WriteString(`"` + text + `"`)
+	return nil
+}
+
+func (e AndExpr) render(b *strings.Builder) error { return renderList(b, e.Exprs, " AND ") }
+
+func (e OrExpr) render(b *strings.Builder) error { return renderList(b, e.Exprs, " OR ") }
+
+func (e NotExpr) render(b *strings.Builder) error { return renderPrefixed(b, "NOT ", e.Expr) }
+
+func (e MustExpr) render(b *strings.Builder) error { return renderPrefixed(b, "+", e.Expr) }
+
+func (e ExcludeExpr) render(b *strings.Builder) error { return renderPrefixed(b, "-", e.Expr) }
+
+func renderList(b *strings.Builder, exprs []Expr, separator string) error {
+	if len(exprs) == 0 {
+		return errors.New("query operator has no operands")
+	}
+	if len(exprs) == 1 {
+		return renderOperand(b, exprs[0], false)
+	}
+	for i, expr := range exprs {
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		_, and := expr.(AndExpr)
+		_, or := expr.(OrExpr)
+		if err := renderOperand(b, expr, and || or); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderPrefixed(b *strings.Builder, prefix string, expr Expr) error {
+	b.WriteString(prefix)
+	switch expr.(type) {
+	case TermExpr, PhraseExpr:
+		return renderOperand(b, expr, false)
+	}
+	return renderOperand(b, expr, true)
+}
+
+// renderOperand renders expr, in parentheses when grouped is set.
+func renderOperand(b *strings.Builder, expr Expr, grouped bool) error {
+	if expr == nil {
+		return errors.New("query operand is nil")
+	}
+	if !grouped {
+		return expr.render(b)
+	}
+	b.WriteString("(")
+	if err := expr.render(b); err != nil {
+		return err
+	}
+	b.WriteString(")")
+	return nil
+}
+
+func needsQuotes(word string) bool {
+	switch word {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	if word[0] == '+' || word[0] == '-' {
+		return true
+	}
+	return strings.ContainsAny(word, "() \t\r\n")
+}
+
+// ParseQ reads a q parameter value into an expression. Terms next to each
+// other without an operator are joined with AND, which binds tighter than
+// OR; NOT, + and - apply to the term or group that follows them.
+func ParseQ(q string) (Expr, error) {
+	tokens, err := tokenizeQ(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("query string is required")
+	}
+	p := &qParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+type qTokenKind int
+
+const (
+	qWord qTokenKind = iota
+	qPhrase
+	qAnd
+	qOr
+	qNot
+	qMust
+	qExclude
+	qOpen
+	qClose
+)
+
+type qToken struct {
+	kind qTokenKind
+	text string
+}
+
+func tokenizeQ(q string) ([]qToken, error) {
+	tokens := []qToken{}
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, qToken{kind: qOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, qToken{kind: qClose, text: ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated phrase in query")
+			}
+			tokens = append(tokens, qToken{kind: qPhrase, text: string(runes[i+1 : end])})
+			i = end + 1
+		case (r == '+' || r == '-') && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			kind := qMust
+			if r == '-' {
+				kind = qExclude
+			}
+			tokens = append(tokens, qToken{kind: kind, text: string(r)})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			switch word {
+			case "AND":
+				tokens = append(tokens, qToken{kind: qAnd, text: word})
+			case "OR":
+				tokens = append(tokens, qToken{kind: qOr, text: word})
+			case "NOT":
+				tokens = append(tokens, qToken{kind: qNot, text: word})
+			default:
+				tokens = append(tokens, qToken{kind: qWord, text: word})
+			}
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type qParser struct {
+	tokens []qToken
+	pos    int
+}
+
+func (p *qParser) peek() (qToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return qToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *qParser) parseOr() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if token, ok := p.peek(); !ok || token.kind != qOr {
+			break
+		}
+		p.pos++
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return OrExpr{Exprs: exprs}, nil
+}
+
+func (p *qParser) parseAnd() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		token, ok := p.peek()
+		if !ok || token.kind == qOr || token.kind == qClose {
+			break
+		}
+		if token.kind == qAnd {
+			p.pos++
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return AndExpr{Exprs: exprs}, nil
+}
+
+func (p *qParser) parseUnary() (Expr, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, errors.New("missing term at end of query")
+	}
+	switch token.kind {
+	case qNot, qMust, qExclude:
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		switch token.kind {
+		case qNot:
+			return NotExpr{Expr: operand}, nil
+		case qMust:
+			return MustExpr{Expr: operand}, nil
+		default:
+			return ExcludeExpr{Expr: operand}, nil
+		}
+	case qWord:
+		p.pos++
+		return TermExpr{Word: token.text}, nil
+	case qPhrase:
+		p.pos++
+		return PhraseExpr{Text: token.text}, nil
+	case qOpen:
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != qClose {
+			return nil, errors.New("unbalanced parentheses in query")
+		}
+		p.pos++
+		return expr, nil
+	case qClose:
+		return nil, errors.New("unbalanced parentheses in query")
+	}
+	return nil, fmt.Errorf("missing term before %q in query", token.text)
+}
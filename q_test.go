@@ -0,0 +1,119 @@
+package news_api_test
+
+import (
+	"strings"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryExpr(t *testing.T) {
+	t.Run("Render expressions", func(t *testing.T) {
+		cases := []struct {
+			want string
+			expr news_api.Expr
+		}{
+			{"apple", news_api.Term("apple")},
+			{`"climate change"`, news_api.Phrase("  climate   change ")},
+			{`"new york"`, news_api.Term("new york")},
+			{`"OR"`, news_api.Term("OR")},
+			{`"-5"`, news_api.Term("-5")},
+			{"covid-19", news_api.Term("covid-19")},
+			{"apple AND iphone", news_api.And(news_api.Term("apple"), news_api.Term("iphone"))},
+			{"apple", news_api.And(news_api.Term("apple"))},
+			{"+bitcoin AND -ethereum", news_api.And(news_api.Must(news_api.Term("bitcoin")), news_api.Exclude(news_api.Term("ethereum")))},
+			{"crypto AND (ethereum OR litecoin) AND NOT bitcoin", news_api.And(news_api.Term("crypto"),
+				news_api.Or(news_api.Term("ethereum"), news_api.Term("litecoin")), news_api.Not(news_api.Term("bitcoin")))},
+			{`+("mac os" OR macos)`, news_api.Must(news_api.Or(news_api.Phrase("mac os"), news_api.Term("macos")))},
+			{"NOT (a AND b)", news_api.Not(news_api.And(news_api.Term("a"), news_api.Term("b")))},
+		}
+		for _, c := range cases {
+			q, err := news_api.RenderQ(c.expr)
+			assert.Nil(t, err)
+			assert.Equal(t, c.want, q)
+			assert.Equal(t, c.want, c.expr.String())
+		}
+	})
+
+	t.Run("Render rejects invalid expressions", func(t *testing.T) {
+		cases := map[string]news_api.Expr{
+			"query string is required":                               nil,
+			"query term is empty":                                    news_api.Term(" "),
+			"query phrase is empty":                                  news_api.And(news_api.Term("a"), news_api.Phrase("")),
+			`query phrase "say \"hi\"" cannot contain double quotes`: news_api.Phrase(`say "hi"`),
+			"query operator has no operands":                         news_api.Or(),
+			"query operand is nil":                                   news_api.Not(nil),
+		}
+		for message, expr := range cases {
+			_, err := news_api.RenderQ(expr)
+			assert.EqualError(t, err, message)
+		}
+	})
+
+	t.Run("Render checks the length limit", func(t *testing.T) {
+		terms := []news_api.Expr{}
+		for i := 0; i < 100; i++ {
+			terms = append(terms, news_api.Term("word"))
+		}
+		_, err := news_api.RenderQ(news_api.Or(terms[:60]...))
+		assert.Nil(t, err)
+		_, err = news_api.RenderQ(news_api.Or(terms...))
+		assert.EqualError(t, err, "query string length should be lessthan equalto 500")
+		_, err = news_api.RenderQ(news_api.Phrase(strings.Repeat("a", 499)))
+		assert.EqualError(t, err, "query string length should be lessthan equalto 500")
+	})
+
+	t.Run("Parse q strings", func(t *testing.T) {
+		cases := map[string]news_api.Expr{
+			"apple":            news_api.Term("apple"),
+			`"climate change"`: news_api.Phrase("climate change"),
+			"apple iphone":     news_api.And(news_api.Term("apple"), news_api.Term("iphone")),
+			"a OR b c":         news_api.Or(news_api.Term("a"), news_api.And(news_api.Term("b"), news_api.Term("c"))),
+			"crypto AND (ethereum OR litecoin) NOT bitcoin": news_api.And(news_api.Term("crypto"),
+				news_api.Or(news_api.Term("ethereum"), news_api.Term("litecoin")), news_api.Not(news_api.Term("bitcoin"))),
+			`+bitcoin -"price drop" covid-19`: news_api.And(news_api.Must(news_api.Term("bitcoin")),
+				news_api.Exclude(news_api.Phrase("price drop")), news_api.Term("covid-19")),
+			"-(a OR b)": news_api.Exclude(news_api.Or(news_api.Term("a"), news_api.Term("b"))),
+			"and or":    news_api.And(news_api.Term("and"), news_api.Term("or")),
+		}
+		for q, want := range cases {
+			expr, err := news_api.ParseQ(q)
+			assert.Nil(t, err, q)
+			assert.Equal(t, want, expr, q)
+		}
+	})
+
+	t.Run("Parse rejects malformed q strings", func(t *testing.T) {
+		cases := map[string]string{
+			"":             "query string is required",
+			`"open phrase`: "unterminated phrase in query",
+			"(a OR b":      "unbalanced parentheses in query",
+			"a OR b)":      `unexpected ")" in query`,
+			"a AND":        "missing term at end of query",
+			"a OR OR b":    `missing term before "OR" in query`,
+			"()":           "unbalanced parentheses in query",
+		}
+		for q, message := range cases {
+			_, err := news_api.ParseQ(q)
+			assert.EqualError(t, err, message, q)
+		}
+	})
+
+	t.Run("Rendered expressions parse back", func(t *testing.T) {
+		exprs := []news_api.Expr{
+			news_api.And(news_api.Phrase("climate change"), news_api.Or(news_api.Term("policy"), news_api.Term("summit")),
+				news_api.Exclude(news_api.Term("opinion"))),
+			news_api.Or(news_api.And(news_api.Term("a"), news_api.Term("b")), news_api.Not(news_api.Phrase("c d"))),
+			news_api.Must(news_api.Not(news_api.Term("x"))),
+		}
+		for _, expr := range exprs {
+			q, err := news_api.RenderQ(expr)
+			assert.Nil(t, err)
+			parsed, err := news_api.ParseQ(q)
+			assert.Nil(t, err, q)
+			assert.Equal(t, expr, parsed, q)
+		}
+	})
+}
@@ -0,0 +1,493 @@
+package news_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is implemented by EverythingQuery, TopHeadlinesQuery and SourcesQuery.
+// Endpoint returns the path of the endpoint relative to the API base URL and
+// Values returns the validated request parameters.
+type Query interface {
+	Endpoint() string
+	Values() (url.Values, error)
+}
+
+type SortBy string
+
+const (
+	SortByPublishedAt SortBy = "publishedAt"
+	SortByPopularity  SortBy = "popularity"
+	SortByRelevancy   SortBy = "relevancy"
+)
+
+type Language string
+
+const (
+	LanguageAR Language = "ar"
+	LanguageDE Language = "de"
+	LanguageEN Language = "en"
+	LanguageES Language = "es"
+	LanguageFR Language = "fr"
+	LanguageHE Language = "he"
+	LanguageIT Language = "it"
+	LanguageNL Language = "nl"
+	LanguageNO Language = "no"
+	LanguagePT Language = "pt"
+	LanguageRU Language = "ru"
+	LanguageSV Language = "sv"
+	LanguageUD Language = "ud"
+	LanguageZH Language = "zh"
+)
+
+type Category string
+
+const (
+	CategoryBusiness      Category = "business"
+	CategoryEntertainment Category = "entertainment"
+	CategoryGeneral       Category = "general"
+	CategoryHealth        Category = "health"
+	CategoryScience       Category = "science"
+	CategorySports        Category = "sports"
+	CategoryTechnology    Category = "technology"
+)
+
+type SearchIn string
+
+const (
+	SearchInTitle       SearchIn = "title"
+	SearchInDescription SearchIn = "description"
+	SearchInContent     SearchIn = "content"
+)
+
+// Country is a two-letter ISO 3166-1 country code such as "us" or "gb".
+type Country string
+
+// EverythingQuery holds the parameters of a /v2/everything request.
+// Zero values are left out of the URL. One of Q, Sources or Domains is
+// required.
+type EverythingQuery struct {
+	Q              string
+	SearchIn       []SearchIn
+	Sources        []string
+	Domains        []string
+	ExcludeDomains []string
+	From           time.Time
+	To             time.Time
+	Language       Language
+	SortBy         SortBy
+	PageSize       int64
+	Page           int64
+}
+
+// TopHeadlinesQuery holds the parameters of a /v2/top-headlines request.
+// Zero values are left out of the URL. Country and Category cannot be
+// combined with Sources and one of Q, Sources, Country or Category is
+// required.
+type TopHeadlinesQuery struct {
+	Q        string
+	Sources  []string
+	Country  Country
+	Category Category
+	PageSize int64
+	Page     int64
+}
+
+// SourcesQuery holds the parameters of a /v2/top-headlines/sources request.
+// Zero values are left out of the URL.
+type SourcesQuery struct {
+	Country  Country
+	Category Category
+	Language Language
+}
+
+// queryFields holds the parameters of every endpoint, with a list for each
+// one, so that all three query types share one encoder and the map passed to
+// ConstructQueryURL can be read before its query type is known.
+type queryFields struct {
+	Q              string
+	SearchIn       []SearchIn
+	Sources        []string
+	Domains        []string
+	ExcludeDomains []string
+	Country        []Country
+	Category       []Category
+	From           time.Time
+	To             time.Time
+	Language       []Language
+	SortBy         SortBy
+	PageSize       int64
+	Page           int64
+}
+
+func (q EverythingQuery) fields() queryFields {
+	return queryFields{
+		Q:              q.Q,
+		SearchIn:       q.SearchIn,
+		Sources:        q.Sources,
+		Domains:        q.Domains,
+		ExcludeDomains: q.ExcludeDomains,
+		From:           q.From,
+		To:             q.To,
+		Language:       singleList(q.Language),
+		SortBy:         q.SortBy,
+		PageSize:       q.PageSize,
+		Page:           q.Page,
+	}
+}
+
+func (q TopHeadlinesQuery) fields() queryFields {
+	return queryFields{
+		Q:        q.Q,
+		Sources:  q.Sources,
+		Country:  singleList(q.Country),
+		Category: singleList(q.Category),
+		PageSize: q.PageSize,
+		Page:     q.Page,
+	}
+}
+
+func (q SourcesQuery) fields() queryFields {
+	return queryFields{
+		Country:  singleList(q.Country),
+		Category: singleList(q.Category),
+		Language: singleList(q.Language),
+	}
+}
+
+// queryFor returns the query type of endpoint holding f. Parameters the
+// endpoint does not accept and all but the first allowed value of a single
+// valued parameter are reported to d and dropped.
+func queryFor(endpoint string, f queryFields, d *diagnostics) Query {
+	f = endpointSchemas[endpoint].filter(endpoint, f, d)
+	switch endpoint {
+	case EndpointEverything:
+		return EverythingQuery{
+			Q:              f.Q,
+			SearchIn:       f.SearchIn,
+			Sources:        f.Sources,
+			Domains:        f.Domains,
+			ExcludeDomains: f.ExcludeDomains,
+			From:           f.From,
+			To:             f.To,
+			Language:       firstValue(f.Language, allowedLanguage, "language", d),
+			SortBy:         f.SortBy,
+			PageSize:       f.PageSize,
+			Page:           f.Page,
+		}
+	case EndpointTopHeadlines:
+		return TopHeadlinesQuery{
+			Q:        f.Q,
+			Sources:  f.Sources,
+			Country:  firstValue(f.Country, allowedCountries, "country", d),
+			Category: firstValue(f.Category, allowedCategories, "category", d),
+			PageSize: f.PageSize,
+			Page:     f.Page,
+		}
+	default:
+		return SourcesQuery{
+			Country:  firstValue(f.Country, allowedCountries, "country", d),
+			Category: firstValue(f.Category, allowedCategories, "category", d),
+			Language: firstValue(f.Language, allowedLanguage, "language", d),
+		}
+	}
+}
+
+func singleList[T ~string](value T) []T {
+	if value == "" {
+		return nil
+	}
+	return []T{value}
+}
+
+// firstValue returns the value encode sends for a single valued parameter:
+// the first of list in allowedArray, or the first of list if none is. The
+// other values are reported for field to d.
+func firstValue[T ~string](list []T, allowedArray []string, field string, d *diagnostics) T {
+	if len(list) == 0 {
+		return ""
+	}
+	first := 0
+	for i, value := range list {
+		if checkIfValueAllowedInStringArray([]string{string(value)}, allowedArray) != "" {
+			first = i
+			break
+		}
+	}
+	for i, value := range list {
+		if i != first {
+			d.add(field, value, "accepts a single value, using "+string(list[first]))
+		}
+	}
+	return list[first]
+}
+
+const defaultBaseURL = "https://newsapi.org/v2/"
+
+// Endpoint paths relative to the API base URL, as returned by Query.Endpoint.
+const (
+	EndpointEverything   = "everything"
+	EndpointTopHeadlines = "top-headlines"
+	EndpointSources      = "top-headlines/sources"
+)
+
+var (
+	// queryParamOrder is the order parameters are written to the URL in.
+	queryParamOrder = []string{"q", "searchIn", "sources", "country", "category", "domains", "excludeDomains", "from", "to", "language", "sortBy", "pageSize", "page"}
+	// escapedQueryParams carry free-form values; everything else is drawn from a
+	// fixed vocabulary, a number or an RFC3339 timestamp and is written as is.
+	escapedQueryParams = map[string]bool{"q": true, "sources": true, "domains": true, "excludeDomains": true}
+)
+
+func (q EverythingQuery) Endpoint() string { return EndpointEverything }
+
+func (q EverythingQuery) Values() (url.Values, error) {
+	return q.fields().encode(EndpointEverything, nil)
+}
+
+func (q TopHeadlinesQuery) Endpoint() string { return EndpointTopHeadlines }
+
+func (q TopHeadlinesQuery) Values() (url.Values, error) {
+	return q.fields().encode(EndpointTopHeadlines, nil)
+}
+
+func (q SourcesQuery) Endpoint() string { return EndpointSources }
+
+func (q SourcesQuery) Values() (url.Values, error) {
+	return q.fields().encode(EndpointSources, nil)
+}
+
+// BuildQueryURL renders query as an absolute NewsAPI URL. By default invalid
+// parameters are dropped or adjusted; see WithStrictValidation and
+// WithWarnings.
+func BuildQueryURL(query Query, opts ...QueryOption) (string, error) {
+	return buildQueryURL(query, &diagnostics{}, newQueryOptions(opts))
+}
+
+func buildQueryURL(query Query, d *diagnostics, options queryOptions) (string, error) {
+	var (
+		values url.Values
+		err    error
+	)
+	if fields, ok := fieldsOf(query); ok {
+		if options.history > 0 {
+			fields, err = fields.limitHistory(query.Endpoint(), options.now().Add(-options.history).UTC().Truncate(time.Second), d)
+		}
+		if err == nil {
+			values, err = fields.encode(query.Endpoint(), d)
+		}
+	} else {
+		values, err = query.Values()
+	}
+	logQuery(context.Background(), options.logger, query.Endpoint(), d.fields, options.strict)
+	if options.strict && len(d.fields) > 0 {
+		return "", &ValidationError{Fields: d.fields}
+	}
+	if options.warnings != nil {
+		*options.warnings = append(*options.warnings, d.fields...)
+	}
+	if err != nil {
+		return "", err
+	}
+	qurl := fmt.Sprintf("%s%s?%s", defaultBaseURL, query.Endpoint(), encodeQueryValues(values))
+	options.logger.LogAttrs(context.Background(), slog.LevelDebug, "newsapi query url built",
+		slog.String("endpoint", query.Endpoint()), slog.String("url", RedactURL(qurl)))
+	return qurl, nil
+}
+
+// fieldsOf returns the fields of the package's own query types.
+func fieldsOf(query Query) (queryFields, bool) {
+	switch q := query.(type) {
+	case EverythingQuery:
+		return q.fields(), true
+	case TopHeadlinesQuery:
+		return q.fields(), true
+	case SourcesQuery:
+		return q.fields(), true
+	}
+	return queryFields{}, false
+}
+
+// endpointFromURL returns the endpoint apiURL points at, such as
+// top-headlines/sources for https://newsapi.org/v2/top-headlines/sources.
+// Relative URLs are resolved against base. It returns "" when apiURL is on
+// another host or its path is not the path of base followed by an endpoint.
+func endpointFromURL(apiURL, base *url.URL) string {
+	resolved := base.ResolveReference(apiURL)
+	if !strings.EqualFold(resolved.Host, base.Host) || !strings.HasPrefix(resolved.Path, base.Path) {
+		return ""
+	}
+	path := strings.TrimSuffix(strings.TrimPrefix(resolved.Path, base.Path), "/")
+	for _, endpoint := range []string{EndpointSources, EndpointTopHeadlines, EndpointEverything} {
+		if path == endpoint {
+			return endpoint
+		}
+	}
+	return ""
+}
+
+// encode validates the fields against the schema of endpoint and encodes
+// them, reporting every parameter it drops or adjusts to d. A blank or
+// oversized q and a missing required parameter are errors.
+func (f queryFields) encode(endpoint string, d *diagnostics) (url.Values, error) {
+	values, err := f.sanitize(endpoint, d)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// sanitize filters, restricts and clamps the fields the way encode does and
+// returns the values encode sends, along with its error if any.
+func (f queryFields) sanitize(endpoint string, d *diagnostics) (url.Values, error) {
+	schema := endpointSchemas[endpoint]
+	f = schema.filter(endpoint, f, d)
+	values := url.Values{}
+	var qErr error
+	q := strings.TrimSpace(f.Q)
+	if len(q) > int(maxQstringLength) {
+		qErr = errors.New("query string length should be lessthan equalto 500")
+	} else if len(q) < 1 && f.Q != "" {
+		qErr = errors.New("query string length should be greaterthan equalto 1")
+	} else if q != "" {
+		values.Set("q", q)
+	}
+	if qErr != nil {
+		d.add("q", f.Q, qErr.Error())
+	}
+	setList(values, "searchIn", allowedValues(f.SearchIn, allowedSearchIn, "searchIn", d))
+	if len(f.Sources) > 0 {
+		setList(values, "sources", allowedValues(f.Sources, nil, "sources", d))
+		for _, country := range f.Country {
+			d.add("country", country, "cannot be combined with sources")
+		}
+		for _, category := range f.Category {
+			d.add("category", category, "cannot be combined with sources")
+		}
+	} else {
+		setList(values, "country", allowedValues(f.Country, allowedCountries, "country", d))
+		setList(values, "category", allowedValues(f.Category, allowedCategories, "category", d))
+	}
+	setList(values, "domains", allowedValues(f.Domains, nil, "domains", d))
+	setList(values, "excludeDomains", allowedValues(f.ExcludeDomains, nil, "excludeDomains", d))
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		d.add("to", formatQueryTime(f.To), "to date is before from date "+formatQueryTime(f.From))
+	} else {
+		if !f.From.IsZero() {
+			values.Set("from", formatQueryTime(f.From))
+		}
+		if !f.To.IsZero() {
+			values.Set("to", formatQueryTime(f.To))
+		}
+	}
+	setList(values, "language", allowedValues(f.Language, allowedLanguage, "language", d))
+	if f.SortBy != "" {
+		sortBy := ""
+		for _, allowedValue := range allowedSortBys {
+			if strings.EqualFold(allowedValue, string(f.SortBy)) {
+				sortBy = allowedValue
+				break
+			}
+		}
+		if sortBy == "" {
+			d.add("sortBy", f.SortBy, "not an allowed value, using "+defaultSortBy)
+			sortBy = defaultSortBy
+		}
+		values.Set("sortBy", sortBy)
+	}
+	if f.PageSize != 0 {
+		pageSize := f.PageSize
+		if pageSize < 1 {
+			d.add("pageSize", pageSize, fmt.Sprintf("should be greaterthan equalto 1, using %d", defaultPageSize))
+			pageSize = defaultPageSize
+		}
+		if pageSize > maxpageSize {
+			d.add("pageSize", pageSize, fmt.Sprintf("should be lessthan equalto %d", maxpageSize))
+			pageSize = maxpageSize
+		}
+		values.Set("pageSize", strconv.FormatInt(pageSize, 10))
+	}
+	if f.Page != 0 {
+		page := f.Page
+		if page < 1 {
+			d.add("page", page, fmt.Sprintf("should be greaterthan equalto 1, using %d", defaultPage))
+			page = defaultPage
+		}
+		values.Set("page", strconv.FormatInt(page, 10))
+	}
+	if qErr != nil {
+		return values, qErr
+	}
+	return values, schema.restrict(values, d)
+}
+
+func formatQueryTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func setList(values url.Values, key string, list []string) {
+	if len(list) > 0 {
+		values.Set(key, strings.Join(list, ","))
+	}
+}
+
+// allowedValues lowercases and filters list against allowedArray, reporting
+// dropped values for field to d. An empty allowedArray accepts every
+// non-blank value unchanged.
+func allowedValues[T ~string](list []T, allowedArray []string, field string, d *diagnostics) []string {
+	strArr := make([]string, 0, len(list))
+	for _, value := range list {
+		if strings.TrimSpace(string(value)) == "" {
+			continue
+		}
+		strArr = append(strArr, string(value))
+		if len(allowedArray) > 0 && checkIfValueAllowedInStringArray([]string{string(value)}, allowedArray) == "" {
+			d.add(field, value, "not an allowed value")
+		}
+	}
+	queryString := checkIfValueAllowedInStringArray(strArr, allowedArray)
+	if queryString == "" {
+		return nil
+	}
+	return strings.Split(queryString, ",")
+}
+
+// encodeQueryValues writes values in queryParamOrder followed by any unknown
+// parameters in sorted order.
+func encodeQueryValues(values url.Values) string {
+	parts := []string{}
+	seen := map[string]bool{}
+	for _, key := range queryParamOrder {
+		seen[key] = true
+		if value := values.Get(key); value != "" {
+			parts = append(parts, encodeQueryParam(key, value))
+		}
+	}
+	extra := []string{}
+	for key := range values {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		if value := values.Get(key); value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", urlEncodeString(key), urlEncodeString(value)))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func encodeQueryParam(key, value string) string {
+	if escapedQueryParams[key] {
+		value = urlEncodeString(value)
+	}
+	return fmt.Sprintf("%s=%s", key, value)
+}
@@ -0,0 +1,90 @@
+package news_api_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedQueries(t *testing.T) {
+
+	t.Run("Build everything url from typed query", func(t *testing.T) {
+		qurl, err := news_api.BuildQueryURL(news_api.EverythingQuery{
+			Q:        "apple & pear",
+			SearchIn: []news_api.SearchIn{news_api.SearchInTitle, "contenting"},
+			Domains:  []string{"abc.com", "xyz.com"},
+			From:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 1, 5, 20, 34, 5, 0, time.FixedZone("IST", 5*3600+1800)),
+			Language: news_api.LanguageEN,
+			SortBy:   news_api.SortByPopularity,
+			PageSize: 20,
+			Page:     2,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple+%26+pear&searchIn=title&domains=abc.com%2Cxyz.com"+
+			"&from=2024-01-02T00:00:00Z&to=2024-01-05T15:04:05Z&language=en&sortBy=popularity&pageSize=20&page=2", qurl)
+	})
+
+	t.Run("Typed queries report their endpoint", func(t *testing.T) {
+		assert.Equal(t, "everything", news_api.EverythingQuery{}.Endpoint())
+		assert.Equal(t, "top-headlines", news_api.TopHeadlinesQuery{}.Endpoint())
+		assert.Equal(t, "top-headlines/sources", news_api.SourcesQuery{}.Endpoint())
+	})
+
+	t.Run("Typed query values are validated", func(t *testing.T) {
+		values, err := news_api.TopHeadlinesQuery{
+			Q:        "apple",
+			Country:  "us",
+			Category: "cat",
+		}.Values()
+		assert.Nil(t, err)
+		assert.Equal(t, "us", values.Get("country"))
+		assert.Equal(t, "", values.Get("category"))
+
+		_, err = news_api.EverythingQuery{}.Values()
+		assert.EqualError(t, err, "one of q, sources or domains is required")
+	})
+
+	t.Run("Zero page and pageSize are left out", func(t *testing.T) {
+		qurl, err := news_api.BuildQueryURL(news_api.EverythingQuery{Q: "apple"})
+		assert.Nil(t, err)
+		assert.Equal(t, false, strings.Contains(qurl, "page"))
+	})
+
+	t.Run("Construct url accepts any integer type for paging", func(t *testing.T) {
+		for _, page := range []interface{}{2, int32(2), int64(2), uint(2)} {
+			qurl, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "page": page, "pageSize": page})
+			assert.Nil(t, err)
+			assert.Equal(t, true, strings.Contains(qurl, "&pageSize=2&page=2"))
+		}
+	})
+
+	t.Run("Construct url accepts typed values", func(t *testing.T) {
+		qurl, err := news_api.ConstructQueryURL("top-headlines", map[string]interface{}{"q": "apple",
+			"category": []news_api.Category{news_api.CategoryHealth}})
+		assert.Nil(t, err)
+		assert.Equal(t, true, strings.Contains(qurl, "category=health"))
+		qurl, err = news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple",
+			"from": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)})
+		assert.Nil(t, err)
+		assert.Equal(t, true, strings.Contains(qurl, "from=2024-01-02T00:00:00Z"))
+	})
+
+	t.Run("Construct url converts the map into the typed query", func(t *testing.T) {
+		params := map[string]interface{}{"q": "apple", "searchIn": []string{"title"}, "country": []string{"xx", "us", "gb"},
+			"category": "business", "language": "en", "sortBy": "popularity", "pageSize": 10}
+		qurl, err := news_api.ConstructQueryURL("everything", params)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&searchIn=title&language=en&sortBy=popularity&pageSize=10", qurl)
+		qurl, err = news_api.ConstructQueryURL("top-headlines", params)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?q=apple&country=us&category=business&pageSize=10", qurl)
+		qurl, err = news_api.ConstructQueryURL("sources", params)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines/sources?country=us&category=business&language=en", qurl)
+	})
+}
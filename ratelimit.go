@@ -0,0 +1,162 @@
+package news_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is matched by the *QuotaError returned once the daily
+// quota set with WithDailyQuota has been used up.
+var ErrQuotaExhausted = errors.New("daily request quota exhausted")
+
+// QuotaError is returned without contacting NewsAPI when the daily quota is
+// used up. ResetAt is the next UTC midnight.
+type QuotaError struct {
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("daily request quota of %d exhausted, resets at %s", e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExhausted
+}
+
+// WithRateLimit spreads requests out to at most rps per second with bursts of
+// up to burst requests. Requests wait for a free slot or until their context
+// is done. The limit is shared by every goroutine using the client.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(rep *newsAPI) error {
+		if rps <= 0 || math.IsInf(rps, 0) || math.IsNaN(rps) {
+			return errors.New("rate limit should be greaterthan 0")
+		}
+		if burst < 1 {
+			return errors.New("rate limit burst should be greaterthan equalto 1")
+		}
+		rep.limiter.bucket = &tokenBucket{rate: rps, burst: float64(burst), tokens: float64(burst)}
+		return nil
+	}
+}
+
+// WithDailyQuota allows at most limit requests per UTC day. Retries count
+// against the quota. Once it is used up requests fail with a *QuotaError.
+func WithDailyQuota(limit int) Option {
+	return func(rep *newsAPI) error {
+		if limit < 1 {
+			return errors.New("daily quota should be greaterthan equalto 1")
+		}
+		rep.limiter.quota = &dailyQuota{limit: limit}
+		return nil
+	}
+}
+
+type limiter struct {
+	bucket *tokenBucket
+	quota  *dailyQuota
+	now    func() time.Time
+}
+
+// wait blocks until a request may be sent. The quota is checked first so an
+// exhausted quota fails fast instead of waiting for the rate limit.
+func (l *limiter) wait(ctx context.Context) error {
+	now := l.now()
+	if l.quota != nil {
+		if err := l.quota.take(now); err != nil {
+			return err
+		}
+	}
+	if l.bucket == nil {
+		return nil
+	}
+	delay := l.bucket.reserve(now)
+	if err := sleepContext(ctx, delay); err != nil {
+		l.bucket.cancel()
+		if l.quota != nil {
+			l.quota.refund(now)
+		}
+		return err
+	}
+	return nil
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token, letting the balance go negative, and returns how
+// long the caller has to wait for it to be covered.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+type dailyQuota struct {
+	mu    sync.Mutex
+	limit int
+	used  int
+	day   time.Time
+}
+
+func (q *dailyQuota) take(now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover(now)
+	if q.used >= q.limit {
+		return &QuotaError{Limit: q.limit, ResetAt: q.day.AddDate(0, 0, 1)}
+	}
+	q.used++
+	return nil
+}
+
+// refund gives back a request taken at takenAt. Nothing is given back when
+// the quota has moved on to a later day since, as that day's count does not
+// include the request.
+func (q *dailyQuota) refund(takenAt time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !utcDay(takenAt).Equal(q.day) {
+		return
+	}
+	if q.used > 0 {
+		q.used--
+	}
+}
+
+func (q *dailyQuota) rollover(now time.Time) {
+	if day := utcDay(now); day.After(q.day) {
+		q.day = day
+		q.used = 0
+	}
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,143 @@
+package news_api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	t.Run("Daily quota fails fast once exhausted", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		now := time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC)
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithDailyQuota(2))
+		assert.Nil(t, err)
+		news_api.SetClock(newsAPI, func() time.Time { return now })
+		for i := 0; i < 2; i++ {
+			_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+			assert.Nil(t, err)
+		}
+		_, err = newsAPI.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Equal(t, true, errors.Is(err, news_api.ErrQuotaExhausted))
+		var quotaErr *news_api.QuotaError
+		assert.Equal(t, true, errors.As(err, &quotaErr))
+		assert.Equal(t, 2, quotaErr.Limit)
+		assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), quotaErr.ResetAt)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		now = now.Add(2 * time.Hour)
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+	})
+
+	t.Run("Daily quota is shared across goroutines", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithDailyQuota(5))
+		assert.Nil(t, err)
+		var (
+			wg        sync.WaitGroup
+			exhausted int32
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple"); errors.Is(err, news_api.ErrQuotaExhausted) {
+					atomic.AddInt32(&exhausted, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+		assert.Equal(t, int32(15), atomic.LoadInt32(&exhausted))
+	})
+
+	t.Run("Token bucket spaces out requests", func(t *testing.T) {
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithRateLimit(20, 2))
+		assert.Nil(t, err)
+		started := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, true, time.Since(started) >= 90*time.Millisecond)
+	})
+
+	t.Run("Waiting for the rate limit respects the context", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithRateLimit(0.1, 1))
+		assert.Nil(t, err)
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = newsAPI.GetNewsContext(ctx, "https://newsapi.org/v2/everything?q=apple")
+		assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("A refund after midnight keeps the new day's count", func(t *testing.T) {
+		var clock atomic.Int64
+		clock.Store(time.Date(2024, 1, 2, 23, 59, 55, 0, time.UTC).UnixNano())
+		newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL),
+			news_api.WithRateLimit(0.1, 1), news_api.WithDailyQuota(2))
+		assert.Nil(t, err)
+		news_api.SetClock(newsAPI, func() time.Time { return time.Unix(0, clock.Load()) })
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+
+		// The second request takes the last quota slot of the day and waits
+		// for the rate limit until its context ends, after midnight.
+		waiting := make(chan error)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			_, err := newsAPI.GetNewsContext(ctx, "https://newsapi.org/v2/everything?q=apple")
+			waiting <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		clock.Store(time.Date(2024, 1, 3, 0, 0, 30, 0, time.UTC).UnixNano())
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, true, errors.Is(<-waiting, context.DeadlineExceeded))
+
+		clock.Store(time.Date(2024, 1, 3, 0, 1, 0, 0, time.UTC).UnixNano())
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Nil(t, err)
+		clock.Store(time.Date(2024, 1, 3, 0, 2, 0, 0, time.UTC).UnixNano())
+		_, err = newsAPI.GetNews("https://newsapi.org/v2/everything?q=apple")
+		assert.Equal(t, true, errors.Is(err, news_api.ErrQuotaExhausted))
+	})
+
+	t.Run("Invalid limits", func(t *testing.T) {
+		_, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithRateLimit(0, 1))
+		assert.EqualError(t, err, "rate limit should be greaterthan 0")
+		_, err = news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithRateLimit(1, 0))
+		assert.EqualError(t, err, "rate limit burst should be greaterthan equalto 1")
+		_, err = news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithDailyQuota(0))
+		assert.EqualError(t, err, "daily quota should be greaterthan equalto 1")
+	})
+}
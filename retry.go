@@ -0,0 +1,145 @@
+package news_api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how GetNews and GetSources retry failed requests.
+// Timeouts, refused and reset connections, 5xx and 429 responses and the
+// rateLimited and unexpectedError codes are retried; every other error, such
+// as the apiKeyInvalid or parameterInvalid codes or a body that is not valid
+// JSON, is returned at once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Each further retry
+	// multiplies it by Multiplier, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the fraction of each delay, between 0 and 1, that is randomised.
+	Jitter float64
+}
+
+// DefaultRetryPolicy makes up to three attempts starting with a 500ms backoff.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy enables automatic retries. A Retry-After header is honored
+// when present; if it asks for a longer wait than MaxBackoff the error is
+// returned instead of retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(rep *newsAPI) error {
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+			return errors.New("retry backoff should be greaterthan equalto 0")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return errors.New("retry jitter should be between 0 and 1")
+		}
+		rep.retryPolicy = policy
+		return nil
+	}
+}
+
+var retryableCodes = map[string]bool{
+	CodeRateLimited:     true,
+	CodeUnexpectedError: true,
+}
+
+// nextDelay reports whether the failed attempt should be retried and how
+// long to wait first.
+func (p RetryPolicy) nextDelay(ctx context.Context, attempt int, resp *apiResponse, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+		return 0, false
+	}
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.header.Get("Retry-After"), time.Now()); ok {
+			if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+				return 0, false
+			}
+			return retryAfter, true
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	delay -= delay * p.Jitter * rand.Float64()
+	return time.Duration(delay)
+}
+
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return retryableCodes[apiErr.Code]
+	}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests || respErr.StatusCode >= 500
+	}
+	// Cancellation of the caller's context has already been ruled out, so
+	// timeouts here are per attempt.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// parseRetryAfter reads a Retry-After value given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+package news_api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var calls int32
+	failures := map[string]func(w http.ResponseWriter){
+		"/v2/bad-gateway": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>bad gateway</html>"))
+		},
+		"/v2/rate-limited": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"rateLimited","message":"slow down"}`))
+		},
+		"/v2/long-retry-after": func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		"/v2/invalid-key": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","code":"apiKeyInvalid","message":"bad key"}`))
+		},
+		"/v2/broken-json": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"ok","totalResults":`))
+		},
+		"/v2/invalid-parameter": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","code":"parameterInvalid","message":"bad parameter"}`))
+		},
+	}
+	var failFirst int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		if call <= atomic.LoadInt32(&failFirst) {
+			failures[r.URL.Path](w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","totalResults":%d}`, call)
+	}))
+	defer server.Close()
+
+	reset := func(n int32) {
+		atomic.StoreInt32(&calls, 0)
+		atomic.StoreInt32(&failFirst, n)
+	}
+	policy := news_api.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+	newsAPI, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL), news_api.WithRetryPolicy(policy))
+	assert.Nil(t, err)
+
+	t.Run("5xx responses are retried", func(t *testing.T) {
+		reset(2)
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/bad-gateway?q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.TotalResults)
+	})
+
+	t.Run("Rate limited responses are retried", func(t *testing.T) {
+		reset(1)
+		resp, err := newsAPI.GetNews("https://newsapi.org/v2/rate-limited?q=apple")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.TotalResults)
+	})
+
+	t.Run("Attempts are bounded", func(t *testing.T) {
+		reset(5)
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/rate-limited?q=apple")
+		assert.Equal(t, true, news_api.IsRateLimited(err))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Non retryable codes fail at once", func(t *testing.T) {
+		for _, path := range []string{"/v2/invalid-key", "/v2/invalid-parameter"} {
+			reset(5)
+			_, err := newsAPI.GetNews("https://newsapi.org" + path + "?q=apple")
+			var apiErr *news_api.APIError
+			assert.Equal(t, true, errors.As(err, &apiErr))
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		}
+	})
+
+	t.Run("Retry-After longer than MaxBackoff is not waited for", func(t *testing.T) {
+		reset(5)
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/long-retry-after?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Network errors are retried", func(t *testing.T) {
+		reset(0)
+		transport := &flakyTransport{failures: 2, err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}
+		flaky, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL),
+			news_api.WithRetryPolicy(policy), news_api.WithTransport(transport))
+		assert.Nil(t, err)
+		_, err = flaky.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, transport.calls)
+
+		transport = &flakyTransport{failures: 2, err: errors.New("tls: bad certificate")}
+		flaky, err = news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL),
+			news_api.WithRetryPolicy(policy), news_api.WithTransport(transport))
+		assert.Nil(t, err)
+		_, err = flaky.GetSources("https://newsapi.org/v2/top-headlines/sources")
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, transport.calls)
+	})
+
+	t.Run("Decode errors are not retried", func(t *testing.T) {
+		reset(1)
+		_, err := newsAPI.GetNews("https://newsapi.org/v2/broken-json?q=apple")
+		var respErr *news_api.ResponseError
+		assert.Equal(t, true, errors.As(err, &respErr))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Cancelled context stops retrying", func(t *testing.T) {
+		reset(5)
+		slow, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL),
+			news_api.WithRetryPolicy(news_api.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second}))
+		assert.Nil(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		started := time.Now()
+		_, err = slow.GetNewsContext(ctx, "https://newsapi.org/v2/bad-gateway?q=apple")
+		assert.NotNil(t, err)
+		assert.Equal(t, true, time.Since(started) < time.Second)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Retries are off by default", func(t *testing.T) {
+		reset(1)
+		plain, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithBaseURL(server.URL))
+		assert.Nil(t, err)
+		_, err = plain.GetNews("https://newsapi.org/v2/bad-gateway?q=apple")
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		_, err := news_api.InitializeNewsAPI("xxxxxxxxxxxxxxxxxxxxxxxx", news_api.WithRetryPolicy(news_api.RetryPolicy{Jitter: 2}))
+		assert.EqualError(t, err, "retry jitter should be between 0 and 1")
+	})
+}
+
+type flakyTransport struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
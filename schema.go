@@ -0,0 +1,170 @@
+package news_api
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// endpointSchema lists the parameters an endpoint accepts, those of them that
+// take a single value rather than a comma separated list, and the parameters
+// of which at least one must be set. pageSize is the page size NewsAPI uses
+// when none is given.
+type endpointSchema struct {
+	params   []string
+	single   []string
+	required []string
+	missing  error
+	pageSize int64
+}
+
+var endpointSchemas = map[string]endpointSchema{
+	EndpointEverything: {
+		params:   []string{"q", "searchIn", "sources", "domains", "excludeDomains", "from", "to", "language", "sortBy", "pageSize", "page"},
+		single:   []string{"language"},
+		required: []string{"q", "sources", "domains"},
+		missing:  errors.New("one of q, sources or domains is required"),
+		pageSize: 100,
+	},
+	EndpointTopHeadlines: {
+		params:   []string{"q", "sources", "country", "category", "pageSize", "page"},
+		single:   []string{"country", "category"},
+		required: []string{"q", "sources", "country", "category"},
+		missing:  errors.New("one of q, sources, country or category is required"),
+		pageSize: 20,
+	},
+	EndpointSources: {
+		params: []string{"country", "category", "language"},
+		single: []string{"country", "category", "language"},
+	},
+}
+
+// EndpointParams returns the parameters endpoint accepts, in URL order, or nil for
+// an unknown endpoint.
+func EndpointParams(endpoint string) []string {
+	return append([]string(nil), endpointSchemas[endpoint].params...)
+}
+
+// ParamValues returns the values NewsAPI accepts for param, or nil for a
+// parameter that takes any value.
+func ParamValues(param string) []string {
+	var values []string
+	switch param {
+	case "searchIn":
+		values = allowedSearchIn
+	case "country":
+		values = allowedCountries
+	case "category":
+		values = allowedCategories
+	case "language":
+		values = allowedLanguage
+	case "sortBy":
+		values = allowedSortBys
+	}
+	return append([]string(nil), values...)
+}
+
+func (s endpointSchema) allows(param string) bool {
+	return contains(s.params, param)
+}
+
+// filter clears the fields the endpoint does not accept, reporting each one
+// that was set to d.
+func (s endpointSchema) filter(endpoint string, f queryFields, d *diagnostics) queryFields {
+	reason := "not supported by " + endpoint
+	if f.Q != "" && !s.allows("q") {
+		d.add("q", f.Q, reason)
+		f.Q = ""
+	}
+	if len(f.SearchIn) > 0 && !s.allows("searchIn") {
+		d.add("searchIn", joinList(f.SearchIn), reason)
+		f.SearchIn = nil
+	}
+	if len(f.Sources) > 0 && !s.allows("sources") {
+		d.add("sources", joinList(f.Sources), reason)
+		f.Sources = nil
+	}
+	if len(f.Domains) > 0 && !s.allows("domains") {
+		d.add("domains", joinList(f.Domains), reason)
+		f.Domains = nil
+	}
+	if len(f.ExcludeDomains) > 0 && !s.allows("excludeDomains") {
+		d.add("excludeDomains", joinList(f.ExcludeDomains), reason)
+		f.ExcludeDomains = nil
+	}
+	if len(f.Country) > 0 && !s.allows("country") {
+		d.add("country", joinList(f.Country), reason)
+		f.Country = nil
+	}
+	if len(f.Category) > 0 && !s.allows("category") {
+		d.add("category", joinList(f.Category), reason)
+		f.Category = nil
+	}
+	if !f.From.IsZero() && !s.allows("from") {
+		d.add("from", formatQueryTime(f.From), reason)
+		f.From = time.Time{}
+	}
+	if !f.To.IsZero() && !s.allows("to") {
+		d.add("to", formatQueryTime(f.To), reason)
+		f.To = time.Time{}
+	}
+	if len(f.Language) > 0 && !s.allows("language") {
+		d.add("language", joinList(f.Language), reason)
+		f.Language = nil
+	}
+	if f.SortBy != "" && !s.allows("sortBy") {
+		d.add("sortBy", f.SortBy, reason)
+		f.SortBy = ""
+	}
+	if f.PageSize != 0 && !s.allows("pageSize") {
+		d.add("pageSize", f.PageSize, reason)
+		f.PageSize = 0
+	}
+	if f.Page != 0 && !s.allows("page") {
+		d.add("page", f.Page, reason)
+		f.Page = 0
+	}
+	return f
+}
+
+// restrict keeps the first value of the single valued parameters and checks
+// that a required parameter is present.
+func (s endpointSchema) restrict(values map[string][]string, d *diagnostics) error {
+	for _, param := range s.single {
+		list := strings.Split(strings.Join(values[param], ","), ",")
+		if len(list) < 2 {
+			continue
+		}
+		for _, value := range list[1:] {
+			d.add(param, value, "accepts a single value, using "+list[0])
+		}
+		values[param] = []string{list[0]}
+	}
+	if len(s.required) == 0 {
+		return nil
+	}
+	for _, param := range s.required {
+		if len(values[param]) > 0 {
+			return nil
+		}
+	}
+	d.add(s.required[0], "", s.missing.Error())
+	return s.missing
+}
+
+func joinList[T ~string](list []T) string {
+	strArr := make([]string, 0, len(list))
+	for _, value := range list {
+		strArr = append(strArr, string(value))
+	}
+	return strings.Join(strArr, ",")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
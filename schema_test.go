@@ -0,0 +1,86 @@
+package news_api_test
+
+import (
+	"errors"
+	"testing"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointSchema(t *testing.T) {
+	t.Run("Only the endpoint's parameters are sent", func(t *testing.T) {
+		params := map[string]interface{}{
+			"q": "apple", "searchIn": "title", "domains": "bbc.co.uk", "country": "us", "category": "business",
+			"from": "2024-01-02T00:00:00Z", "language": "en", "sortBy": "relevancy", "pageSize": 10, "page": 2,
+		}
+		qurl, err := news_api.ConstructQueryURL("everything", params)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&searchIn=title&domains=bbc.co.uk"+
+			"&from=2024-01-02T00:00:00Z&language=en&sortBy=relevancy&pageSize=10&page=2", qurl)
+
+		qurl, err = news_api.ConstructQueryURL("top-headlines", params)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?q=apple&country=us&category=business&pageSize=10&page=2", qurl)
+
+		warnings := []news_api.FieldError{}
+		qurl, err = news_api.ConstructQueryURL("sources", params, news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines/sources?country=us&category=business&language=en", qurl)
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "q", Value: "apple", Reason: "not supported by top-headlines/sources"},
+			{Field: "searchIn", Value: "title", Reason: "not supported by top-headlines/sources"},
+			{Field: "domains", Value: "bbc.co.uk", Reason: "not supported by top-headlines/sources"},
+			{Field: "from", Value: "2024-01-02T00:00:00Z", Reason: "not supported by top-headlines/sources"},
+			{Field: "sortBy", Value: "relevancy", Reason: "not supported by top-headlines/sources"},
+			{Field: "pageSize", Value: "10", Reason: "not supported by top-headlines/sources"},
+			{Field: "page", Value: "2", Reason: "not supported by top-headlines/sources"},
+		}, warnings)
+
+		assert.Equal(t, []string{"country", "category", "language"}, news_api.EndpointParams(news_api.EndpointSources))
+		assert.Nil(t, news_api.EndpointParams("everywhere"))
+		assert.Equal(t, []string{"title", "description", "content"}, news_api.ParamValues("searchIn"))
+		assert.Contains(t, news_api.ParamValues("country"), "us")
+		assert.Nil(t, news_api.ParamValues("q"))
+	})
+
+	t.Run("Required parameters", func(t *testing.T) {
+		for _, query := range []news_api.Query{
+			news_api.EverythingQuery{Sources: []string{"bbc-news"}},
+			news_api.EverythingQuery{Domains: []string{"bbc.co.uk"}},
+			news_api.TopHeadlinesQuery{Category: news_api.CategoryScience},
+			news_api.TopHeadlinesQuery{Country: "de"},
+			news_api.SourcesQuery{},
+		} {
+			_, err := news_api.BuildQueryURL(query, news_api.WithStrictValidation())
+			assert.Nil(t, err)
+		}
+		_, err := news_api.BuildQueryURL(news_api.EverythingQuery{Language: news_api.LanguageEN})
+		assert.EqualError(t, err, "one of q, sources or domains is required")
+		_, err = news_api.BuildQueryURL(news_api.TopHeadlinesQuery{PageSize: 10}, news_api.WithStrictValidation())
+		assert.EqualError(t, err, "invalid query: q: one of q, sources, country or category is required")
+	})
+
+	t.Run("Sources cannot be mixed with country or category", func(t *testing.T) {
+		query := news_api.TopHeadlinesQuery{Sources: []string{"bbc-news"}, Category: news_api.CategoryGeneral}
+		qurl, err := news_api.BuildQueryURL(query)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?sources=bbc-news", qurl)
+		_, err = news_api.BuildQueryURL(query, news_api.WithStrictValidation())
+		var fieldErr news_api.FieldError
+		assert.Equal(t, true, errors.As(err, &fieldErr))
+		assert.Equal(t, news_api.FieldError{Field: "category", Value: "general", Reason: "cannot be combined with sources"}, fieldErr)
+	})
+
+	t.Run("Single valued parameters", func(t *testing.T) {
+		warnings := []news_api.FieldError{}
+		qurl, err := news_api.ConstructQueryURL("top-headlines", map[string]interface{}{"country": []string{"us", "gb"}}, news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?country=us", qurl)
+		assert.Equal(t, []news_api.FieldError{{Field: "country", Value: "gb", Reason: "accepts a single value, using us"}}, warnings)
+
+		_, err = news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "language": []string{"en", "fr"}}, news_api.WithStrictValidation())
+		assert.EqualError(t, err, `invalid query: language: accepts a single value, using en (got "fr")`)
+	})
+}
@@ -0,0 +1,104 @@
+package news_api
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// FieldError describes one query parameter that was rejected or adjusted:
+// the parameter name, the offending value and why it was not used as given.
+type FieldError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s (got %q)", e.Field, e.Reason, e.Value)
+}
+
+// ValidationError is returned by ConstructQueryURL and BuildQueryURL in strict
+// mode and lists every parameter that failed validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return "invalid query: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the field errors so that errors.As can reach a FieldError.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		errs = append(errs, field)
+	}
+	return errs
+}
+
+// QueryOption configures how ConstructQueryURL and BuildQueryURL validate a
+// query.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	strict   bool
+	warnings *[]FieldError
+	logger   *slog.Logger
+	now      func() time.Time
+	history  time.Duration
+	baseURL  string
+}
+
+func newQueryOptions(opts []QueryOption) queryOptions {
+	options := queryOptions{logger: discardLogger, now: time.Now, baseURL: defaultBaseURL}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithStrictValidation makes invalid parameters an error. Instead of dropping
+// or adjusting them, the query is rejected with a *ValidationError.
+func WithStrictValidation() QueryOption {
+	return func(o *queryOptions) {
+		o.strict = true
+	}
+}
+
+// WithWarnings appends to warnings every parameter that lenient validation
+// dropped or adjusted, also when the query then fails with an error.
+func WithWarnings(warnings *[]FieldError) QueryOption {
+	return func(o *queryOptions) {
+		o.warnings = warnings
+	}
+}
+
+// WithQueryBaseURL makes ParseQueryURL accept URLs under baseURL, such as the
+// one given to WithBaseURL, instead of https://newsapi.org/v2/. When the base
+// URL has no path, /v2/ is assumed.
+func WithQueryBaseURL(baseURL string) QueryOption {
+	return func(o *queryOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// diagnostics collects the field problems found while a query is built.
+type diagnostics struct {
+	fields []FieldError
+}
+
+func (d *diagnostics) add(field string, value interface{}, reason string) {
+	if d == nil {
+		return
+	}
+	d.fields = append(d.fields, FieldError{Field: field, Value: fmt.Sprint(value), Reason: reason})
+}
@@ -0,0 +1,115 @@
+package news_api_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	news_api "github.com/aekam27/newsAPIWrapper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	params := map[string]interface{}{
+		"q":        "apple",
+		"country":  []string{"us", "xx"},
+		"category": "sport",
+		"language": "english",
+		"from":     "yesterday",
+		"sortBy":   "newest",
+		"pageSize": 500,
+		"colour":   "red",
+	}
+	expected := []news_api.FieldError{
+		{Field: "colour", Value: "red", Reason: "unknown parameter"},
+		{Field: "from", Value: "yesterday", Reason: "not a timestamp, date or relative duration"},
+		{Field: "language", Value: "english", Reason: "not supported by top-headlines"},
+		{Field: "sortBy", Value: "newest", Reason: "not supported by top-headlines"},
+		{Field: "country", Value: "xx", Reason: "accepts a single value, using us"},
+		{Field: "category", Value: "sport", Reason: "not an allowed value"},
+		{Field: "pageSize", Value: "500", Reason: "should be lessthan equalto 100"},
+	}
+
+	t.Run("Strict mode lists every rejected field", func(t *testing.T) {
+		_, err := news_api.ConstructQueryURL("top-headlines", params, news_api.WithStrictValidation())
+		var validationErr *news_api.ValidationError
+		assert.Equal(t, true, errors.As(err, &validationErr))
+		assert.Equal(t, expected, validationErr.Fields)
+		var fieldErr news_api.FieldError
+		assert.Equal(t, true, errors.As(err, &fieldErr))
+		assert.Equal(t, "colour", fieldErr.Field)
+		assert.Equal(t, `invalid query: colour: unknown parameter (got "red"); from: not a timestamp, date or relative duration (got "yesterday"); `+
+			`language: not supported by top-headlines (got "english"); sortBy: not supported by top-headlines (got "newest"); `+
+			`country: accepts a single value, using us (got "xx"); category: not an allowed value (got "sport"); `+
+			`pageSize: should be lessthan equalto 100 (got "500")`, err.Error())
+	})
+
+	t.Run("Strict mode includes missing parameters with the other fields", func(t *testing.T) {
+		_, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"page": -1, "pageSize": "10"}, news_api.WithStrictValidation())
+		var validationErr *news_api.ValidationError
+		assert.Equal(t, true, errors.As(err, &validationErr))
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "pageSize", Value: "10", Reason: "unsupported type string"},
+			{Field: "page", Value: "-1", Reason: "should be greaterthan equalto 1, using 1"},
+			{Field: "q", Value: "", Reason: "one of q, sources or domains is required"},
+		}, validationErr.Fields)
+	})
+
+	t.Run("Strict mode accepts valid queries", func(t *testing.T) {
+		qurl, err := news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "language": "EN", "pageSize": 20}, news_api.WithStrictValidation())
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&language=en&pageSize=20", qurl)
+	})
+
+	t.Run("Lenient mode returns warnings", func(t *testing.T) {
+		warnings := []news_api.FieldError{}
+		qurl, err := news_api.ConstructQueryURL("top-headlines", params, news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/top-headlines?q=apple&country=us&pageSize=100", qurl)
+		assert.Equal(t, expected, warnings)
+
+		qurl2, err := news_api.ConstructQueryURL("top-headlines", params)
+		assert.Nil(t, err)
+		assert.Equal(t, qurl, qurl2)
+
+		warnings = []news_api.FieldError{}
+		qurl, err = news_api.ConstructQueryURL("everything", map[string]interface{}{"q": "apple", "language": "english", "sortBy": "newest"},
+			news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&sortBy=publishedAt", qurl)
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "language", Value: "english", Reason: "not an allowed value"},
+			{Field: "sortBy", Value: "newest", Reason: "not an allowed value, using publishedAt"},
+		}, warnings)
+
+		warnings = []news_api.FieldError{}
+		_, err = news_api.ConstructQueryURL("everything", map[string]interface{}{"q": " ", "colour": "red"}, news_api.WithWarnings(&warnings))
+		assert.EqualError(t, err, "query string length should be greaterthan equalto 1")
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "colour", Value: "red", Reason: "unknown parameter"},
+			{Field: "q", Value: " ", Reason: "query string length should be greaterthan equalto 1"},
+		}, warnings)
+	})
+
+	t.Run("Typed queries", func(t *testing.T) {
+		from := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+		query := news_api.EverythingQuery{Q: "apple", Sources: []string{"bbc-news"}, From: from, To: from.Add(-time.Hour)}
+		_, err := news_api.BuildQueryURL(query, news_api.WithStrictValidation())
+		var validationErr *news_api.ValidationError
+		assert.Equal(t, true, errors.As(err, &validationErr))
+		assert.Equal(t, []news_api.FieldError{
+			{Field: "to", Value: "2024-01-09T23:00:00Z", Reason: "to date is before from date 2024-01-10T00:00:00Z"},
+		}, validationErr.Fields)
+
+		warnings := []news_api.FieldError{}
+		qurl, err := news_api.BuildQueryURL(query, news_api.WithWarnings(&warnings))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://newsapi.org/v2/everything?q=apple&sources=bbc-news", qurl)
+		assert.Equal(t, 1, len(warnings))
+
+		_, err = news_api.BuildQueryURL(news_api.TopHeadlinesQuery{Q: "apple", Sources: []string{"bbc-news"}, Country: "gb"},
+			news_api.WithStrictValidation())
+		assert.EqualError(t, err, `invalid query: country: cannot be combined with sources (got "gb")`)
+	})
+}